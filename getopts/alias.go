@@ -0,0 +1,113 @@
+package getopts
+
+import "fmt"
+import "unicode/utf8"
+
+//How many aliases may expand to other aliases before giving up, so
+//aliases that refer to each other do not loop forever.
+const maxAliasDepth = 10
+
+const(
+	errAliasDepth = "Alias expansion too deep:  %s"
+)
+
+//Make -s and --l shorthand for the arguments in expansion, which are parsed
+//as if they had been passed instead, so with expansion -x -y --mode=fast,
+//-a sets x, y, and mode.  Either s or l may be left out by passing 0 or
+//an empty string.  An alias must be passed on its own, not in a clump.
+//Expansions may use other aliases, up to a depth of ten.
+func (ps *Parser)RegisterAlias(s rune, l string, expansion []string) {
+	if s != 0 {
+		ps.checkShort(s)
+		ps.aliasesByShort[s] = expansion
+	}
+	if l != "" {
+		ps.checkLong(l)
+		ps.aliasesByLong[l] = expansion
+	}
+}
+
+//Register an alias with CommandLine.
+func RegisterAlias(s rune, l string, expansion []string) {
+	commandLine().RegisterAlias(s, l, expansion)
+}
+
+//Give a registered flag or option the extra long option l, so --colour
+//sets the same option as --color.  Help shows only the first long option
+//unless SetHelpShowLongNames is on.  Panics if p is not registered or l is
+//taken.
+func (ps *Parser)AddLongName(p Param, l string) {
+	param, ok := ps.paramFor(p.common())
+	if !ok {
+		panic("Adding a long name to an option that is not registered")
+	}
+	if l == "" {
+		panic("Long name must not be empty")
+	}
+	ps.checkLong(l)
+	ps.paramsByLong[l] = param
+	o := param.base()
+	o.longNames = append(o.longNames, l)
+}
+
+//Give a flag or option of CommandLine another long option.
+func AddLongName(p Param, l string) {
+	commandLine().AddLongName(p, l)
+}
+
+//Every long option naming o:  LongOpt, if any, then those given to
+//AddLongName.
+func (o *option)longs() []string {
+	if o.LongOpt == "" {
+		return o.longNames
+	}
+	return append([]string{ o.LongOpt }, o.longNames...)
+}
+
+//The registered parameter for o.
+func (ps *Parser)paramFor(o *option) (parameter, bool) {
+	for _, p := range ps.params {
+		if p.base() == o {
+			return p, true
+		}
+	}
+	return parameter{}, false
+}
+
+//Expansion of arg if it is an alias.
+func (ps *Parser)lookupAlias(arg string) ([]string, bool) {
+	if len(arg) > 1 && arg[0] == '-' && arg[1] != '-' {
+		s, width := utf8.DecodeRuneInString(arg[1:])
+		if 1 + width != len(arg) {
+			return nil, false
+		}
+		expansion, ok := ps.aliasesByShort[s]
+		return expansion, ok
+	}
+	if len(arg) > 2 && arg[0] == '-' && arg[1] == '-' {
+		expansion, ok := ps.aliasesByLong[arg[2:]]
+		return expansion, ok
+	}
+	return nil, false
+}
+
+//Replace every alias in args by its expansion, recursively.
+func (ps *Parser)expandAliases(args []string, depth int) ([]string, error) {
+	expanded := make([]string, 0, len(args))
+	for _, arg := range args {
+		expansion, ok := ps.lookupAlias(arg)
+		if !ok {
+			expanded = append(expanded, arg)
+			continue
+		}
+		if depth >= maxAliasDepth {
+			return nil, fmt.Errorf(errAliasDepth, arg)
+		}
+		more, err := ps.expandAliases(expansion, depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, more...)
+	}
+	return expanded, nil
+}
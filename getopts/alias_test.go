@@ -0,0 +1,33 @@
+package getopts
+
+import "testing"
+
+//An alias is parsed as its expansion
+func TestRegisterAlias(t *testing.T) {
+	resetParams()
+	x := NewFlagShort('x', "Extract")
+	y := NewFlagShort('y', "Yes")
+	mode := NewOptionLong("mode", "Mode")
+	RegisterAlias('a', "all", []string{ "-x", "-y", "--mode=fast" })
+	rest, err := ArgParse([]string{ "test", "-a", "file" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if !x.Passed || !y.Passed || mode.OptArg != "fast" {
+		t.Fatalf("-a should set x, y, and mode")
+	}
+	if len(rest) != 1 || rest[0].Argument != "file" {
+		t.Fatalf("Got %v expected file", rest)
+	}
+}
+
+//Aliases that expand to each other are an error rather than a loop
+func TestAliasLoop(t *testing.T) {
+	resetParams()
+	RegisterAlias('a', "", []string{ "-b" })
+	RegisterAlias('b', "", []string{ "-a" })
+	_, err := ArgParse([]string{ "test", "-a" })
+	if err == nil {
+		t.Fatalf("Looping aliases should be an error")
+	}
+}
@@ -0,0 +1,377 @@
+package getopts
+
+import "fmt"
+import "encoding"
+import "reflect"
+import "strings"
+import "time"
+import "unicode"
+import "unicode/utf8"
+
+const(
+	errBindTarget = "Bind needs a pointer to a struct, got %T"
+	errBindTag = "Field %s has malformed getopts tag:  %s"
+	errBindType = "Field %s has unsupported type %s"
+	errTextDefault = "Default of %s cannot be stored:  %v"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+//Short option, long option, and help from a tag like "v,verbose,Help".
+//Help may contain commas.
+func parseBindTag(tag string) (rune, string, string, bool) {
+	fields := strings.SplitN(tag, ",", 3)
+	for len(fields) < 3 {
+		fields = append(fields, "")
+	}
+	if utf8.RuneCountInString(fields[0]) > 1 || (fields[0] == "" && fields[1] == "") {
+		return 0, "", "", false
+	}
+	var s rune
+	if fields[0] != "" {
+		s, _ = utf8.DecodeRuneInString(fields[0])
+	}
+	return s, fields[1], fields[2], true
+}
+
+//Whether Bind can register a field of type t.
+func bindable(t reflect.Type) bool {
+	if t == durationType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Float64, reflect.String:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+//Register a flag or option for field, and return a function copying its
+//value into field after parsing.
+func (ps *Parser)bindField(field reflect.Value, s rune, l, h string) func() {
+	if field.Type() == durationType {
+		opt := ps.NewDurationOption(s, l, h)
+		return func() {
+			if opt.Passed {
+				field.SetInt(int64(opt.Value))
+			}
+		}
+	}
+	switch field.Kind() {
+	case reflect.Bool:
+		flag := ps.newFlag(s, l, h)
+		return func() {
+			if flag.Passed || flag.Count != 0 {
+				field.SetBool(flag.Passed)
+			}
+		}
+	case reflect.Int:
+		opt := ps.NewIntOption(s, l, h)
+		return func() {
+			if opt.Passed {
+				field.SetInt(int64(opt.Value))
+			}
+		}
+	case reflect.Float64:
+		opt := ps.NewFloat64Option(s, l, h)
+		return func() {
+			if opt.Passed {
+				field.SetFloat(opt.Value)
+			}
+		}
+	case reflect.String:
+		opt := ps.newOption(s, l, h)
+		return func() {
+			if opt.Passed {
+				field.SetString(opt.OptArg)
+			}
+		}
+	default:
+		opt := ps.newOption(s, l, h)
+		return func() {
+			if opt.Passed {
+				//Element by element, since the elements may be of a
+				//named string type
+				values := reflect.MakeSlice(field.Type(), len(opt.OptArgs), len(opt.OptArgs))
+				for i, arg := range opt.OptArgs {
+					values.Index(i).SetString(arg)
+				}
+				field.Set(values)
+			}
+		}
+	}
+}
+
+//Register a flag or option for every field of the struct v points to with a
+//tag like `getopts:"v,verbose,Increase verbosity"`, giving the short
+//option, long option, and help.  Either name may be left empty.  bool fields
+//become flags; int, float64, time.Duration, and string fields become options
+//taking that type; []string fields collect every opt-arg.  Each parse copies
+//the values of the flags and options that were passed into their fields,
+//so values already in the struct act as defaults.  If a tag is malformed,
+//a field has another type, or a name is already used, nothing is registered
+//and an error is returned.
+func (ps *Parser)Bind(v any) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf(errBindTarget, v)
+	}
+	target = target.Elem()
+	specs := make([]Spec, 0)
+	fields := make([]reflect.Value, 0)
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		tag, ok := field.Tag.Lookup("getopts")
+		if !ok {
+			continue
+		}
+		s, l, h, ok := parseBindTag(tag)
+		if !ok {
+			return fmt.Errorf(errBindTag, field.Name, tag)
+		}
+		if !field.IsExported() || !bindable(field.Type) {
+			return fmt.Errorf(errBindType, field.Name, field.Type)
+		}
+		spec := Spec{
+			Short:	s,
+			Long:	l,
+			Help:	h,
+			Type:	SpecOption,
+		}
+		if field.Type.Kind() == reflect.Bool {
+			spec.Type = SpecFlag
+		}
+		specs = append(specs, spec)
+		fields = append(fields, target.Field(i))
+	}
+	if err := ps.checkSpecs(specs); err != nil {
+		return err
+	}
+	for i, spec := range specs {
+		field := fields[i]
+		original := reflect.New(field.Type()).Elem()
+		original.Set(field)
+		ps.bound = append(ps.bound, ps.bindField(field, spec.Short, spec.Long, spec.Help))
+		ps.unbound = append(ps.unbound, func() {
+			field.Set(original)
+		})
+	}
+	return nil
+}
+
+//Register the tagged fields of the struct v points to with CommandLine.
+func Bind(v any) error {
+	defer syncCommandLine()
+	return commandLine().Bind(v)
+}
+
+//Copy parsed values into bound struct fields.
+func (ps *Parser)populateBound() {
+	for _, populate := range ps.bound {
+		populate()
+	}
+}
+
+//Register an option that stores its most recent opt-arg in *p, like
+//flag.StringVar.  *p is set to value, which is also the default.
+func (ps *Parser)StringVar(p *string, s rune, l string, value string, h string) *Option {
+	opt := ps.newOption(s, l, h)
+	opt.Default = value
+	*p = value
+	ps.bound = append(ps.bound, func() {
+		if opt.Passed {
+			*p = opt.OptArg
+		}
+	})
+	ps.unbound = append(ps.unbound, func() {
+		*p = value
+	})
+	return opt
+}
+
+func StringVar(p *string, s rune, l string, value string, h string) *Option {
+	defer syncCommandLine()
+	return commandLine().StringVar(p, s, l, value, h)
+}
+
+//Register an option whose integer argument is stored in *p, like
+//flag.IntVar.  *p is set to value, which is also the default.
+func (ps *Parser)IntVar(p *int, s rune, l string, value int, h string) *IntOption {
+	opt := ps.NewIntOption(s, l, h)
+	opt.SetDefault(value)
+	*p = value
+	ps.bound = append(ps.bound, func() {
+		if opt.Passed {
+			*p = opt.Value
+		}
+	})
+	ps.unbound = append(ps.unbound, func() {
+		*p = value
+	})
+	return opt
+}
+
+func IntVar(p *int, s rune, l string, value int, h string) *IntOption {
+	defer syncCommandLine()
+	return commandLine().IntVar(p, s, l, value, h)
+}
+
+//Register a flag whose value is stored in *p, like flag.BoolVar.  *p is set
+//to value and changes only if the flag is passed or negated.
+func (ps *Parser)BoolVar(p *bool, s rune, l string, value bool, h string) *Flag {
+	flag := ps.newFlag(s, l, h)
+	*p = value
+	ps.bound = append(ps.bound, func() {
+		if flag.Passed || flag.Count != 0 {
+			*p = flag.Passed
+		}
+	})
+	ps.unbound = append(ps.unbound, func() {
+		*p = value
+	})
+	return flag
+}
+
+func BoolVar(p *bool, s rune, l string, value bool, h string) *Flag {
+	defer syncCommandLine()
+	return commandLine().BoolVar(p, s, l, value, h)
+}
+
+//Register an option whose duration argument is stored in *p, like
+//flag.DurationVar.  *p is set to value, which is also the default.
+func (ps *Parser)DurationVar(p *time.Duration, s rune, l string, value time.Duration, h string) *DurationOption {
+	opt := ps.NewDurationOption(s, l, h)
+	opt.SetDefault(value)
+	*p = value
+	ps.bound = append(ps.bound, func() {
+		if opt.Passed {
+			*p = opt.Value
+		}
+	})
+	ps.unbound = append(ps.unbound, func() {
+		*p = value
+	})
+	return opt
+}
+
+func DurationVar(p *time.Duration, s rune, l string, value time.Duration, h string) *DurationOption {
+	defer syncCommandLine()
+	return commandLine().DurationVar(p, s, l, value, h)
+}
+
+//Adapts a TextUnmarshaler to Value.
+type textValue struct {
+	p	encoding.TextUnmarshaler
+}
+
+func (v textValue)Set(s string) error {
+	return v.p.UnmarshalText([]byte(s))
+}
+
+func (v textValue)String() string {
+	if m, ok := v.p.(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+	return ""
+}
+
+//Register an option whose opt-args are passed to p.UnmarshalText, like
+//flag.TextVar, so types like netip.Addr, slog.Level, or a UUID need no
+//Value of their own.  p is set to value, which is also the default.
+//Panics if value cannot be marshaled, or p cannot unmarshal it.
+func (ps *Parser)TextVar(p encoding.TextUnmarshaler, s rune, l string, value encoding.TextMarshaler, h string) *ValueOption {
+	text, err := value.MarshalText()
+	if err == nil {
+		err = p.UnmarshalText(text)
+	}
+	if err != nil {
+		name := l
+		if name == "" {
+			name = string(s)
+		}
+		panic(fmt.Sprintf(errTextDefault, name, err))
+	}
+	opt := ps.NewValueOption(s, l, h, textValue{ p: p })
+	opt.Default = string(text)
+	opt.typed = func() any {
+		return p
+	}
+	return opt
+}
+
+func TextVar(p encoding.TextUnmarshaler, s rune, l string, value encoding.TextMarshaler, h string) *ValueOption {
+	defer syncCommandLine()
+	return commandLine().TextVar(p, s, l, value, h)
+}
+
+//Adapts a BinaryUnmarshaler to Value.
+type binaryValue struct {
+	p	encoding.BinaryUnmarshaler
+}
+
+func (v binaryValue)Set(s string) error {
+	return v.p.UnmarshalBinary([]byte(s))
+}
+
+func (v binaryValue)String() string {
+	if m, ok := v.p.(encoding.BinaryMarshaler); ok {
+		data, err := m.MarshalBinary()
+		if err == nil && printable(data) {
+			return string(data)
+		}
+	}
+	return ""
+}
+
+//Whether data is text that can be shown as it is.
+func printable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, c := range string(data) {
+		if !unicode.IsPrint(c) {
+			return false
+		}
+	}
+	return true
+}
+
+//Register an option whose opt-args are passed to p.UnmarshalBinary as the
+//bytes of the argument, for types that implement BinaryUnmarshaler but not
+//TextUnmarshaler.  p is set to value, which is also the default.  Help
+//shows the default only if it is printable text.  Panics if value cannot
+//be marshaled, or p cannot unmarshal it.
+func (ps *Parser)BinaryVar(p encoding.BinaryUnmarshaler, s rune, l string, value encoding.BinaryMarshaler, h string) *ValueOption {
+	data, err := value.MarshalBinary()
+	if err == nil {
+		err = p.UnmarshalBinary(data)
+	}
+	if err != nil {
+		name := l
+		if name == "" {
+			name = string(s)
+		}
+		panic(fmt.Sprintf(errTextDefault, name, err))
+	}
+	opt := ps.NewValueOption(s, l, h, binaryValue{ p: p })
+	if printable(data) {
+		opt.Default = string(data)
+	}
+	opt.typed = func() any {
+		return p
+	}
+	ps.unbound = append(ps.unbound, func() {
+		p.UnmarshalBinary(data)
+	})
+	return opt
+}
+
+func BinaryVar(p encoding.BinaryUnmarshaler, s rune, l string, value encoding.BinaryMarshaler, h string) *ValueOption {
+	defer syncCommandLine()
+	return commandLine().BinaryVar(p, s, l, value, h)
+}
@@ -0,0 +1,177 @@
+package getopts
+
+import "testing"
+import "time"
+import "strings"
+import "errors"
+import "net/netip"
+
+//Tagged struct fields are registered and filled in by parsing
+func TestBind(t *testing.T) {
+	resetParams()
+	var config struct {
+		Verbose	bool		`getopts:"v,verbose,Increase verbosity"`
+		Jobs	int		`getopts:"j,jobs,Number of jobs, at least one"`
+		Ratio	float64		`getopts:",ratio,Compression ratio"`
+		Timeout	time.Duration	`getopts:"t,,Time to wait"`
+		Output	string		`getopts:"o,output,Output file"`
+		Include	[]string	`getopts:"I,,Include directory"`
+		Ignored	string
+	}
+	config.Output = "out.txt"
+	if err := Bind(&config); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	_, err := ArgParse([]string{ "test", "-vj4", "--ratio=0.5", "-t2s", "-Ia", "-Ib" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if !config.Verbose || config.Jobs != 4 || config.Ratio != 0.5 {
+		t.Fatalf("Got %+v", config)
+	}
+	if config.Timeout != 2 * time.Second || config.Output != "out.txt" {
+		t.Fatalf("Got %+v", config)
+	}
+	if len(config.Include) != 2 || config.Include[1] != "b" {
+		t.Fatalf("Got includes %v", config.Include)
+	}
+	if Parameters()[1].Help != "Number of jobs, at least one" {
+		t.Fatalf("Help should keep its commas, got %s", Parameters()[1].Help)
+	}
+}
+
+//Bad targets and tags are errors that register nothing
+func TestBindErrors(t *testing.T) {
+	resetParams()
+	var notStruct int
+	if err := Bind(&notStruct); err == nil {
+		t.Fatalf("Expected error binding an int")
+	}
+	var badTag struct {
+		Verbose	bool	`getopts:"vv,verbose,Increase verbosity"`
+	}
+	if err := Bind(&badTag); err == nil {
+		t.Fatalf("Expected error for a two letter short option")
+	}
+	var badType struct {
+		Quiet	bool		`getopts:"q,quiet,Say less"`
+		Limits	map[string]int	`getopts:"l,limits,Limits"`
+	}
+	err := Bind(&badType)
+	if err == nil || err.Error() != "Field Limits has unsupported type map[string]int" {
+		t.Fatalf("Got error %v", err)
+	}
+	if len(CommandLine.params) != 0 {
+		t.Fatalf("Failed bind should register nothing")
+	}
+}
+
+//Var constructors write into the given variables, leaving defaults in
+//those that are not passed
+func TestVars(t *testing.T) {
+	resetParams()
+	var name, mode string
+	var jobs int
+	var verbose, color bool
+	var timeout time.Duration
+	StringVar(&name, 'n', "name", "anon", "Name to use")
+	StringVar(&mode, 'm', "mode", "fast", "Mode")
+	IntVar(&jobs, 'j', "jobs", 1, "Number of jobs")
+	BoolVar(&verbose, 'v', "verbose", false, "Increase verbosity")
+	BoolVar(&color, 'c', "color", true, "Colorize output")
+	DurationVar(&timeout, 't', "timeout", time.Second, "Time to wait")
+	if name != "anon" || jobs != 1 || !color || timeout != time.Second {
+		t.Fatalf("Defaults were not stored")
+	}
+	_, err := ArgParse([]string{ "test", "--name=bob", "-j4", "-v", "+c" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if name != "bob" || mode != "fast" || jobs != 4 || !verbose || color || timeout != time.Second {
+		t.Fatalf("Got %s %s %d %v %v %v", name, mode, jobs, verbose, color, timeout)
+	}
+}
+
+//A name of its own for strings
+type bindTag string
+
+//Slices of a named string type collect opt-args like []string
+func TestBindNamedStrings(t *testing.T) {
+	resetParams()
+	var config struct {
+		Tags	[]bindTag	`getopts:"t,tag,Tag to apply"`
+	}
+	if err := Bind(&config); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	_, err := ArgParse([]string{ "test", "-t", "a", "--tag=b" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if len(config.Tags) != 2 || config.Tags[0] != "a" || config.Tags[1] != "b" {
+		t.Fatalf("Got tags %v", config.Tags)
+	}
+}
+
+//TextVar stores opt-args through UnmarshalText
+func TestTextVar(t *testing.T) {
+	resetParams()
+	var addr netip.Addr
+	TextVar(&addr, 'a', "addr", netip.MustParseAddr("127.0.0.1"), "Address to bind")
+	if addr.String() != "127.0.0.1" {
+		t.Fatalf("Default was not stored, got %v", addr)
+	}
+	_, err := ArgParse([]string{ "test", "--addr=::1" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if addr.String() != "::1" {
+		t.Fatalf("Got %v expected ::1", addr)
+	}
+	_, err = ArgParse([]string{ "test", "--addr=localhost" })
+	if err == nil || !strings.HasPrefix(err.Error(), "Argument to option --addr is not valid:  localhost") {
+		t.Fatalf("Got error %v", err)
+	}
+}
+
+//A tag without spaces, stored through the binary encoding
+type binaryTag struct {
+	name	string
+}
+
+func (b *binaryTag)UnmarshalBinary(data []byte) error {
+	if strings.Contains(string(data), " ") {
+		return errors.New("tag contains a space")
+	}
+	b.name = string(data)
+	return nil
+}
+
+func (b binaryTag)MarshalBinary() ([]byte, error) {
+	return []byte(b.name), nil
+}
+
+//BinaryVar stores opt-args through UnmarshalBinary
+func TestBinaryVar(t *testing.T) {
+	resetParams()
+	var tag binaryTag
+	opt := BinaryVar(&tag, 't', "tag", binaryTag{ name: "latest" }, "Image tag")
+	if tag.name != "latest" || opt.Default != "latest" {
+		t.Fatalf("Default was not stored, got %v and '%s'", tag, opt.Default)
+	}
+	_, err := ArgParse([]string{ "test", "--tag=v1.2" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if tag.name != "v1.2" {
+		t.Fatalf("Got %s expected v1.2", tag.name)
+	}
+	_, err = ArgParse([]string{ "test", "--tag=a b" })
+	if err == nil || !strings.HasPrefix(err.Error(), "Argument to option --tag is not valid:  a b") {
+		t.Fatalf("Got error %v", err)
+	}
+	ResetValues()
+	if tag.name != "latest" {
+		t.Fatalf("Got %s expected the default after ResetValues", tag.name)
+	}
+}
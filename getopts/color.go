@@ -0,0 +1,54 @@
+package getopts
+
+import "io"
+import "os"
+
+//Escape sequences for colored help and errors.
+const(
+	ansiBold = "\x1b[1m"
+	ansiDim = "\x1b[2m"
+	ansiRed = "\x1b[31m"
+	ansiReset = "\x1b[0m"
+)
+
+//Whether help and errors are colored.
+type ColorMode int
+
+const(
+	//Never color, the default
+	ColorNever ColorMode = iota
+	//Color output to a terminal, unless $NO_COLOR is set
+	ColorAuto
+	//Always color, even output to a file or pipe
+	ColorAlways
+)
+
+//Color help and errors:  option names bold, their opt-args dim, and
+//errors printed by ExitOnError and MustParse red.
+func (ps *Parser)SetColor(mode ColorMode) {
+	ps.color = mode
+}
+
+//Color help and errors for CommandLine.
+func SetColor(mode ColorMode) {
+	commandLine().SetColor(mode)
+}
+
+//Whether output to w is colored.
+func (ps *Parser)colorFor(w io.Writer) bool {
+	switch ps.color {
+	case ColorAlways:
+		return true
+	case ColorAuto:
+		if os.Getenv("NO_COLOR") != "" {
+			return false
+		}
+		f, ok := w.(*os.File)
+		if !ok {
+			return false
+		}
+		_, ok = terminalSize(f)
+		return ok
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package getopts
+
+import "testing"
+import "strings"
+import "os"
+
+//Colored help makes names bold and opt-args dim, keeping the alignment
+func TestColorHelp(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	NewOptionLong("output", "File to write").Metavar = "FILE"
+	SetColor(ColorAlways)
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	exp := "\x1b[1m-v/--verbose\x1b[0m  Increase verbosity\n" +
+		"\x1b[1m--output\x1b[0m\x1b[2m FILE\x1b[0m File to write\n"
+	if b.String() != exp {
+		t.Fatalf("Got %q expected %q", b.String(), exp)
+	}
+}
+
+//Automatic color is off for anything but a terminal, and with $NO_COLOR
+func TestColorAuto(t *testing.T) {
+	resetParams()
+	SetColor(ColorAuto)
+	var b strings.Builder
+	if CommandLine.colorFor(&b) {
+		t.Fatalf("A strings.Builder should not be colored")
+	}
+	f, err := os.CreateTemp(t.TempDir(), "help")
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	defer f.Close()
+	if CommandLine.colorFor(f) {
+		t.Fatalf("A regular file should not be colored")
+	}
+	t.Setenv("NO_COLOR", "1")
+	SetColor(ColorAlways)
+	if !CommandLine.colorFor(f) {
+		t.Fatalf("ColorAlways should ignore $NO_COLOR")
+	}
+	SetColor(ColorNever)
+	if CommandLine.colorFor(&b) {
+		t.Fatalf("ColorNever should not color")
+	}
+}
@@ -0,0 +1,72 @@
+package getopts
+
+import "unicode/utf8"
+
+//Short options were bytes before they became runes.  These keep code that
+//holds them in byte variables building while it moves to runes.
+
+//Register a flag with a byte short option, as NewFlag did before short
+//options were runes.
+//
+//Deprecated:  use NewFlag with rune(s).
+func (ps *Parser)NewFlagByte(s byte, l string, h string) *Flag {
+	return ps.NewFlag(rune(s), l, h)
+}
+
+//Deprecated:  use NewFlag with rune(s).
+func NewFlagByte(s byte, l string, h string) *Flag {
+	defer syncCommandLine()
+	return commandLine().NewFlagByte(s, l, h)
+}
+
+//Register a flag with only a byte short option, as NewFlagShort did before
+//short options were runes.
+//
+//Deprecated:  use NewFlagShort with rune(s).
+func (ps *Parser)NewFlagShortByte(s byte, h string) *Flag {
+	return ps.NewFlagShort(rune(s), h)
+}
+
+//Deprecated:  use NewFlagShort with rune(s).
+func NewFlagShortByte(s byte, h string) *Flag {
+	defer syncCommandLine()
+	return commandLine().NewFlagShortByte(s, h)
+}
+
+//Register an option with a byte short option, as NewOption did before short
+//options were runes.
+//
+//Deprecated:  use NewOption with rune(s).
+func (ps *Parser)NewOptionByte(s byte, l string, h string) *Option {
+	return ps.NewOption(rune(s), l, h)
+}
+
+//Deprecated:  use NewOption with rune(s).
+func NewOptionByte(s byte, l string, h string) *Option {
+	defer syncCommandLine()
+	return commandLine().NewOptionByte(s, l, h)
+}
+
+//Register an option with only a byte short option, as NewOptionShort did
+//before short options were runes.
+//
+//Deprecated:  use NewOptionShort with rune(s).
+func (ps *Parser)NewOptionShortByte(s byte, h string) *Option {
+	return ps.NewOptionShort(rune(s), h)
+}
+
+//Deprecated:  use NewOptionShort with rune(s).
+func NewOptionShortByte(s byte, h string) *Option {
+	defer syncCommandLine()
+	return commandLine().NewOptionShortByte(s, h)
+}
+
+//ShortOpt as the byte it used to be, or 0 if it is not ASCII.
+//
+//Deprecated:  use ShortOpt, which is a rune.
+func (o *option)ShortByte() byte {
+	if o.ShortOpt >= utf8.RuneSelf {
+		return 0
+	}
+	return byte(o.ShortOpt)
+}
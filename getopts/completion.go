@@ -0,0 +1,91 @@
+package getopts
+
+import "fmt"
+import "io"
+import "strings"
+
+//Name usable as a shell function, with characters other than letters,
+//digits, and underscores turned into underscores.
+func shellIdent(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+//Every form of each registered flag and option as passed on the command
+//line, paired with its help, in help order.
+func (ps *Parser)completionWords() [][2]string {
+	words := make([][2]string, 0)
+	for _, p := range ps.helpOrder() {
+		o := p.base()
+		if o.ShortOpt != 0 {
+			words = append(words, [2]string{ "-" + string(o.ShortOpt), o.Help })
+		}
+		if o.LongOpt != "" {
+			words = append(words, [2]string{ "--" + o.LongOpt, o.Help })
+		}
+	}
+	return words
+}
+
+//Write a zsh completion script for the program, using _describe so every
+//option is offered with its help as the description.  Install it as
+//_<program> in a directory on $fpath.
+func (ps *Parser)GenerateZshCompletion(w io.Writer) error {
+	prog := ps.programName()
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n\n", prog)
+	fmt.Fprintf(&b, "_%s() {\n", shellIdent(prog))
+	b.WriteString("\tlocal -a opts\n")
+	b.WriteString("\topts=(\n")
+	for _, word := range ps.completionWords() {
+		name := strings.ReplaceAll(word[0], ":", "\\:")
+		fmt.Fprintf(&b, "\t\t%s\n", shellQuote(name + ":" + word[1]))
+	}
+	b.WriteString("\t)\n")
+	b.WriteString("\t_describe 'option' opts\n")
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "_%s \"$@\"\n", shellIdent(prog))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+//Write a zsh completion script for CommandLine.
+func GenerateZshCompletion(w io.Writer) error {
+	return commandLine().GenerateZshCompletion(w)
+}
+
+//Write fish completions for the program, one complete command per flag or
+//option, like complete -c prog -s v -l verbose -d 'Increase verbosity'.
+//Options taking an argument are marked with -r.
+func (ps *Parser)GenerateFishCompletion(w io.Writer) error {
+	prog := ps.programName()
+	for _, p := range ps.helpOrder() {
+		o := p.base()
+		line := "complete -c " + shellQuote(prog)
+		if o.ShortOpt != 0 {
+			line += " -s " + shellQuote(string(o.ShortOpt))
+		}
+		if o.LongOpt != "" {
+			line += " -l " + shellQuote(o.LongOpt)
+		}
+		if p.takesArgument() {
+			line += " -r"
+		}
+		if o.Help != "" {
+			line += " -d " + shellQuote(o.Help)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Write fish completions for CommandLine.
+func GenerateFishCompletion(w io.Writer) error {
+	return commandLine().GenerateFishCompletion(w)
+}
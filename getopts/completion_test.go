@@ -0,0 +1,50 @@
+package getopts
+
+import "testing"
+import "strings"
+
+//The zsh script describes every form of every option
+func TestGenerateZshCompletion(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	NewOptionLong("output", "Write to the user's file")
+	ArgParse([]string{ "my-tool" })
+	var b strings.Builder
+	if err := GenerateZshCompletion(&b); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	exp := `#compdef my-tool
+
+_my_tool() {
+	local -a opts
+	opts=(
+		'-v:Increase verbosity'
+		'--verbose:Increase verbosity'
+		'--output:Write to the user'\''s file'
+	)
+	_describe 'option' opts
+}
+
+_my_tool "$@"
+`
+	if b.String() != exp {
+		t.Fatalf("Got\n%s\nexpected\n%s", b.String(), exp)
+	}
+}
+
+//Fish gets one complete command per option
+func TestGenerateFishCompletion(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	NewOptionShort('o', "Output file")
+	ArgParse([]string{ "mytool" })
+	var b strings.Builder
+	if err := GenerateFishCompletion(&b); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	exp := "complete -c 'mytool' -s 'v' -l 'verbose' -d 'Increase verbosity'\n" +
+		"complete -c 'mytool' -s 'o' -r -d 'Output file'\n"
+	if b.String() != exp {
+		t.Fatalf("Got\n%s\nexpected\n%s", b.String(), exp)
+	}
+}
@@ -0,0 +1,186 @@
+package getopts
+
+import "os"
+import "fmt"
+import "strings"
+import "strconv"
+import "encoding/json"
+import "path/filepath"
+
+const(
+	errConfigLine = "Malformed line %d in config file %s"
+	errConfigKey = "Unknown option in config file %s:  %s"
+	errConfigValue = "Unsupported value for %s in config file %s"
+)
+
+//Values read from a config file, keyed by long option.
+type configValues map[string][]string
+
+//Remove matching quotes around a value from an INI or TOML file.
+func unquoteConfig(v string) string {
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		if s, err := strconv.Unquote(v); err == nil {
+			return s
+		}
+	}
+	if len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'' {
+		return v[1:len(v)-1]
+	}
+	return v
+}
+
+//Read key = value lines, as in INI files and simple TOML.  Blank lines,
+//comments starting with # or ;, and [section] headers are skipped.  A value
+//in brackets, like ["a", "b"], is a list.
+func parseKeyValues(path string, data string) (configValues, error) {
+	values := make(configValues)
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' || line[0] == '[' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf(errConfigLine, i + 1, path)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && value[0] == '[' && value[len(value)-1] == ']' {
+			for _, v := range strings.Split(value[1:len(value)-1], ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					values[key] = append(values[key], unquoteConfig(v))
+				}
+			}
+		} else {
+			values[key] = append(values[key], unquoteConfig(value))
+		}
+	}
+	return values, nil
+}
+
+//Value from a JSON config file as an opt-arg.
+func jsonValue(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+//Read a JSON object whose values are strings, numbers, booleans, or arrays
+//of them.
+func parseJSONConfig(path string, data []byte) (configValues, error) {
+	raw := make(map[string]any)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("Config file %s:  %w", path, err)
+	}
+	values := make(configValues)
+	for key, v := range raw {
+		list, ok := v.([]any)
+		if !ok {
+			list = []any{ v }
+		}
+		for _, item := range list {
+			s, ok := jsonValue(item)
+			if !ok {
+				return nil, fmt.Errorf(errConfigValue, key, path)
+			}
+			values[key] = append(values[key], s)
+		}
+	}
+	return values, nil
+}
+
+//Read the config file at path, choosing the format by its extension.
+func (ps *Parser)readConfig(path string) (configValues, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var values configValues
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		values, err = parseJSONConfig(path, data)
+	} else {
+		values, err = parseKeyValues(path, string(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+	for key := range values {
+		if _, ok := ps.paramsByLong[key]; !ok || key == "" {
+			return nil, fmt.Errorf(errConfigKey, path, key)
+		}
+	}
+	return values, nil
+}
+
+//Load option values from the config file at path.  Files ending in .json
+//hold a JSON object; any other file holds key = value lines, as in INI
+//files and simple TOML, with # comments.  Keys are long options, and a key
+//repeated, or given a list, supplies several opt-args.  Flags read their
+//value like --flag=value.  During each later parse, an option not passed on
+//the command line or set from the environment takes its value from the
+//file.  An unknown key is an error.
+func (ps *Parser)LoadConfig(path string) error {
+	values, err := ps.readConfig(path)
+	if err != nil {
+		return err
+	}
+	ps.config = values
+	ps.configPath = path
+	return nil
+}
+
+//Load option values for CommandLine from the config file at path.
+func LoadConfig(path string) error {
+	return commandLine().LoadConfig(path)
+}
+
+//Load the config file named by opt, like --config my.conf, after parsing
+//each time opt is passed.  Options passed on the command line still win.
+func (ps *Parser)SetConfigOption(opt *Option) {
+	ps.configOpt = opt
+}
+
+//Load the config file named by opt after each parse of CommandLine.
+func SetConfigOption(opt *Option) {
+	commandLine().SetConfigOption(opt)
+}
+
+//Assign values from the config file to options that were not passed.
+func (ps *Parser)applyConfig() error {
+	if ps.configOpt != nil && ps.configOpt.Passed {
+		if err := ps.LoadConfig(ps.configOpt.OptArg); err != nil {
+			return err
+		}
+	}
+	for _, p := range ps.params {
+		o := p.base()
+		//Given on the command line or by the environment, even if
+		//only to turn a flag off
+		if o.Changed() {
+			continue
+		}
+		ps.origin = Source{ Kind: SourceConfig, Name: ps.configPath }
+		//Any long option of o may be a key, as for --option on the
+		//command line
+		for _, long := range o.longs() {
+			for _, value := range ps.config[long] {
+				var err error
+				if p.opt != nil {
+					err = ps.addOptArg(p.opt, value)
+				} else {
+					err = ps.takeString(p.flag, value)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
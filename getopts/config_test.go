@@ -0,0 +1,85 @@
+package getopts
+
+import "testing"
+import "os"
+import "path/filepath"
+
+//Write contents to a file called name in a temporary directory
+func writeConfig(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	return path
+}
+
+//Values from an INI style file fill in options not passed
+func TestLoadConfig(t *testing.T) {
+	resetParams()
+	output := NewOption('o', "output", "Output file")
+	include := NewOptionLong("include", "Include path")
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	path := writeConfig(t, "my.conf", `
+# Settings
+[main]
+output = "from file.txt"
+include = ["a", 'b']
+verbose = yes
+`)
+	if err := LoadConfig(path); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	_, err := ArgParse([]string{ "test", "-o", "cli.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if output.OptArg != "cli.txt" || len(output.OptArgs) != 1 {
+		t.Fatalf("Command line should win, got %v", output.OptArgs)
+	}
+	if len(include.OptArgs) != 2 || include.OptArgs[1] != "b" || !verbose.Passed {
+		t.Fatalf("Got %v and %v from file", include.OptArgs, verbose.Passed)
+	}
+
+	//Turning a flag off on the command line also wins
+	ResetValues()
+	_, err = ArgParse([]string{ "test", "--verbose=false" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if verbose.Passed {
+		t.Fatalf("verbose = yes in the file overrode --verbose=false")
+	}
+}
+
+//A JSON file named by --config is loaded after parsing
+func TestConfigOption(t *testing.T) {
+	resetParams()
+	config := NewOption('c', "config", "Config file")
+	jobs := NewIntOption('j', "jobs", "Number of jobs")
+	mode := NewOptionLong("mode", "Mode")
+	SetConfigOption(config)
+	path := writeConfig(t, "my.json", `{"jobs": 4, "mode": "fast"}`)
+	_, err := ArgParse([]string{ "test", "--config", path, "--mode=slow" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if jobs.Value != 4 || mode.OptArg != "slow" {
+		t.Fatalf("Got jobs %d and mode %s", jobs.Value, mode.OptArg)
+	}
+}
+
+//Unknown keys and malformed lines are errors
+func TestLoadConfigErrors(t *testing.T) {
+	resetParams()
+	NewOptionLong("output", "Output file")
+	path := writeConfig(t, "bad.ini", "outptu = x\n")
+	err := LoadConfig(path)
+	if err == nil || err.Error() != "Unknown option in config file " + path + ":  outptu" {
+		t.Fatalf("Got error %v", err)
+	}
+	path = writeConfig(t, "bad.ini", "output x\n")
+	err = LoadConfig(path)
+	if err == nil || err.Error() != "Malformed line 1 in config file " + path {
+		t.Fatalf("Got error %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package getopts
+
+import "fmt"
+import "strings"
+
+const(
+	errRequired = "Missing required %s:  %s"
+	errRequiredIf = "%s is required when %s is set"
+	errExclusive = "%s and %s cannot be used together"
+	errRequires = "%s requires %s"
+	errMinAfterDash = "Expected at least %d %s after '--'"
+	errMaxAfterDash = "Expected at most %d %s after '--'"
+)
+
+//Require at least n operands after '--', for tools run like
+//"mytool run -- cmd args...".
+func (ps *Parser)SetMinAfterDash(n int) {
+	ps.minAfterDash = n
+}
+
+//Require at least n operands after '--' for CommandLine.
+func SetMinAfterDash(n int) {
+	commandLine().SetMinAfterDash(n)
+}
+
+//Allow at most n operands after '--'.  A negative n removes the limit.
+func (ps *Parser)SetMaxAfterDash(n int) {
+	ps.maxAfterDash = n
+}
+
+//Allow at most n operands after '--' for CommandLine.
+func SetMaxAfterDash(n int) {
+	commandLine().SetMaxAfterDash(n)
+}
+
+//"argument" or "arguments" to go with n.
+func arguments(n int) string {
+	if n == 1 {
+		return "argument"
+	}
+	return "arguments"
+}
+
+//Require this option to be passed.  If any required options are missing
+//after parsing, the error lists all of them.  A flag set with
+//SetBypassRequired, like --help, skips the check.
+func (o *option)SetRequired(required bool) {
+	o.required = required
+}
+
+//"option" or "options" to go with n.
+func options(n int) string {
+	if n == 1 {
+		return "option"
+	}
+	return "options"
+}
+
+//Names of the required options that were not passed, as they are
+//written on the command line.
+func (ps *Parser)missingRequired() []string {
+	missing := make([]string, 0)
+	for _, p := range ps.params {
+		o := p.base()
+		if o.required && !o.Passed {
+			missing = append(missing, o.display())
+		}
+	}
+	return missing
+}
+
+//Require this option to be passed whenever other is passed, so --upload can
+//be required only when --remote is set.
+func (o *option)SetRequiredIf(other *Flag) {
+	o.requiredIf = other
+}
+
+//Require others to be passed whenever this flag or option is, so
+//out.Requires(format) makes --output without --format an error.  Calls add
+//to the requirements.
+func (o *option)Requires(others ...Param) {
+	for _, other := range others {
+		o.requires = append(o.requires, other.common())
+	}
+}
+
+//Flags and options of which at most one may be passed.
+type ExclusiveGroup struct {
+	members	[]*option
+}
+
+//Allow at most one of params to be passed, so --json and --xml together are
+//an error naming both.
+func (ps *Parser)NewExclusiveGroup(params ...Param) *ExclusiveGroup {
+	group := &ExclusiveGroup{}
+	for _, p := range params {
+		group.members = append(group.members, p.common())
+	}
+	ps.groups = append(ps.groups, group)
+	return group
+}
+
+//Allow at most one of params of CommandLine to be passed.
+func NewExclusiveGroup(params ...Param) *ExclusiveGroup {
+	return commandLine().NewExclusiveGroup(params...)
+}
+
+//Error naming the first two members of group that were passed, or nil.
+func (group *ExclusiveGroup)check() error {
+	var first *option
+	for _, o := range group.members {
+		if !o.Passed {
+			continue
+		}
+		if first != nil {
+			return fmt.Errorf(errExclusive, first.display(), o.display())
+		}
+		first = o
+	}
+	return nil
+}
+
+//Set a function to check rules between options that the built-in constraints
+//cannot express, like "--threads must be at least 4 with --mode=fast".  It runs
+//after all arguments are parsed and the built-in checks pass, and is given
+//the parser, so it can look options up with LookupLong or LookupShort.  An
+//error it returns is returned by Parse unchanged.
+func (ps *Parser)SetFinalValidator(validator func(*Parser) error) {
+	ps.finalValidator = validator
+}
+
+//Set the final validator of CommandLine.
+func SetFinalValidator(validator func(*Parser) error) {
+	commandLine().SetFinalValidator(validator)
+}
+
+//Make passing this flag skip every check after parsing, including
+//required options, limits on operands, and the final validator, so
+//mytool --help works even when --input is normally required.
+func (f *Flag)SetBypassRequired(bypass bool) {
+	f.bypassRequired = bypass
+}
+
+//Whether a flag that skips the checks was passed.
+func (ps *Parser)bypassed() bool {
+	for _, flag := range ps.Flags {
+		if flag.bypassRequired && flag.Passed {
+			return true
+		}
+	}
+	return false
+}
+
+//Check constraints between options after all arguments are parsed.
+func (ps *Parser)checkConstraints() error {
+	if ps.bypassed() {
+		return nil
+	}
+	if missing := ps.missingRequired(); len(missing) > 0 {
+		return fmt.Errorf(errRequired, options(len(missing)), strings.Join(missing, ", "))
+	}
+	for _, p := range ps.params {
+		o := p.base()
+		if o.requiredIf != nil && o.requiredIf.Passed && !o.Passed {
+			return fmt.Errorf(errRequiredIf, o.display(), o.requiredIf.display())
+		}
+		if !o.Passed {
+			continue
+		}
+		for _, other := range o.requires {
+			if !other.Passed {
+				return fmt.Errorf(errRequires, o.display(), other.display())
+			}
+		}
+	}
+	for _, group := range ps.groups {
+		if err := group.check(); err != nil {
+			return err
+		}
+	}
+	if ps.afterDashCount < ps.minAfterDash {
+		return fmt.Errorf(errMinAfterDash, ps.minAfterDash, arguments(ps.minAfterDash))
+	}
+	if ps.maxAfterDash >= 0 && ps.afterDashCount > ps.maxAfterDash {
+		return fmt.Errorf(errMaxAfterDash, ps.maxAfterDash, arguments(ps.maxAfterDash))
+	}
+	if ps.finalValidator != nil {
+		return ps.finalValidator(ps)
+	}
+	return nil
+}
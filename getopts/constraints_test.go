@@ -0,0 +1,165 @@
+package getopts
+
+import "testing"
+import "errors"
+
+//An option required by a flag is only required when that flag is set
+func TestRequiredIf(t *testing.T) {
+	resetParams()
+	remote := NewFlagLong("remote", "Use remote")
+	upload := NewOptionLong("upload", "Upload destination")
+	upload.SetRequiredIf(remote)
+	_, err := ArgParse([]string{ "test" })
+	if err != nil {
+		t.Fatalf("--upload not required without --remote, got %s", err)
+	}
+
+	_, err = ArgParse([]string{ "test", "--remote" })
+	if err == nil {
+		t.Fatalf("--upload should be required with --remote")
+	}
+	exp := "--upload is required when --remote is set"
+	if err.Error() != exp {
+		t.Fatalf("Got error '%s' expected '%s'", err, exp)
+	}
+
+	_, err = ArgParse([]string{ "test", "--remote", "--upload", "host" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+}
+
+//The final validator can reject combinations of options
+func TestFinalValidator(t *testing.T) {
+	resetParams()
+	NewOptionLong("mode", "Speed")
+	NewOptionLong("threads", "Number of threads")
+	SetFinalValidator(func(ps *Parser) error {
+		mode := ps.LookupLong("mode").(*Option)
+		n, _ := ps.LookupLong("threads").(*Option).Int()
+		if mode.OptArg == "fast" && n < 4 {
+			return errors.New("--threads must be at least 4 with --mode=fast")
+		}
+		return nil
+	})
+	_, err := ArgParse([]string{ "test", "--mode=fast", "--threads=8" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	_, err = ArgParse([]string{ "test", "--mode=fast", "--threads=2" })
+	if err == nil || err.Error() != "--threads must be at least 4 with --mode=fast" {
+		t.Fatalf("Got error %v, expected validator error", err)
+	}
+}
+
+//Limits on the number of operands after '--'
+func TestAfterDashLimits(t *testing.T) {
+	resetParams()
+	SetMinAfterDash(1)
+	SetMaxAfterDash(2)
+	cases := []struct {
+		argv	[]string
+		err	string
+	}{
+		{ []string{ "test", "run", "--" }, "Expected at least 1 argument after '--'" },
+		{ []string{ "test", "run", "--", "ls" }, "" },
+		{ []string{ "test", "run", "--", "ls", "-l" }, "" },
+		{ []string{ "test", "run", "--", "ls", "-l", "/" }, "Expected at most 2 arguments after '--'" },
+	}
+	for _, c := range cases {
+		_, err := ArgParse(c.argv)
+		if c.err == "" && err != nil {
+			t.Fatalf("%v: Error %s", c.argv, err)
+		}
+		if c.err != "" && (err == nil || err.Error() != c.err) {
+			t.Fatalf("%v: Got error %v expected '%s'", c.argv, err, c.err)
+		}
+	}
+}
+
+//A bypass flag skips the checks after parsing
+func TestBypassRequired(t *testing.T) {
+	resetParams()
+	help := NewFlag('h', "help", "Show help")
+	help.SetBypassRequired(true)
+	remote := NewFlagLong("remote", "Use remote")
+	upload := NewOptionLong("upload", "Upload destination")
+	upload.SetRequiredIf(remote)
+	SetMinAfterDash(1)
+	_, err := ArgParse([]string{ "test", "--remote" })
+	if err == nil {
+		t.Fatalf("Missing --upload should be an error without --help")
+	}
+	_, err = ArgParse([]string{ "test", "--remote", "--help" })
+	if err != nil {
+		t.Fatalf("--help should skip checks, got %s", err)
+	}
+}
+
+//Every missing required option is reported, unless --help is passed
+func TestRequired(t *testing.T) {
+	resetParams()
+	help := NewFlag('h', "help", "Show help")
+	help.SetBypassRequired(true)
+	input := NewOption('i', "input", "Input file")
+	input.SetRequired(true)
+	output := NewOptionShort('o', "Output file")
+	output.SetRequired(true)
+	_, err := ArgParse([]string{ "test" })
+	if err == nil || err.Error() != "Missing required options:  --input, -o" {
+		t.Fatalf("Got error %v", err)
+	}
+	_, err = ArgParse([]string{ "test", "-o", "out.txt" })
+	if err == nil || err.Error() != "Missing required option:  --input" {
+		t.Fatalf("Got error %v", err)
+	}
+	ResetValues()
+	_, err = ArgParse([]string{ "test", "-i", "in.txt", "-o", "out.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	ResetValues()
+	_, err = ArgParse([]string{ "test", "--help" })
+	if err != nil {
+		t.Fatalf("--help should skip checks, got %s", err)
+	}
+}
+
+//Passing more than one member of an exclusive group is an error
+func TestExclusiveGroup(t *testing.T) {
+	resetParams()
+	json := NewFlagLong("json", "JSON output")
+	xml := NewFlagLong("xml", "XML output")
+	format := NewOption('f', "format", "Other output format")
+	NewExclusiveGroup(json, xml, format)
+	_, err := ArgParse([]string{ "test", "--xml" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	ResetValues()
+	_, err = ArgParse([]string{ "test", "--json", "-f", "csv", "--xml" })
+	if err == nil || err.Error() != "--json and --xml cannot be used together" {
+		t.Fatalf("Got error %v", err)
+	}
+}
+
+//An option requiring another is an error without it
+func TestRequires(t *testing.T) {
+	resetParams()
+	output := NewOption('o', "output", "Output file")
+	format := NewOptionLong("format", "Output format")
+	output.Requires(format)
+	_, err := ArgParse([]string{ "test" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	_, err = ArgParse([]string{ "test", "-o", "out.txt" })
+	if err == nil || err.Error() != "--output requires --format" {
+		t.Fatalf("Got error %v", err)
+	}
+	ResetValues()
+	_, err = ArgParse([]string{ "test", "-o", "out.txt", "--format=csv" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+}
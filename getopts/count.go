@@ -0,0 +1,47 @@
+package getopts
+
+import "strconv"
+
+//A count flag and an integer option that set the same value.
+type countLink struct {
+	flag	*Flag
+	opt	*Option
+}
+
+//Make flag and opt two ways to give one level, so -vv and --verbosity=2 are
+//equivalent.  After parsing, the level is the larger of the flag's count
+//and the option's value, regardless of the order they were passed in.  Both
+//then hold it:  flag.Count is the level and opt.OptArg is its decimal form.
+//It is an error for the option's value not to be an integer.
+func (ps *Parser)LinkCountToOption(flag *Flag, opt *Option) {
+	ps.countLinks = append(ps.countLinks, countLink{
+		flag:	flag,
+		opt:	opt,
+	})
+}
+
+//Link a count flag and an integer option of CommandLine.
+func LinkCountToOption(flag *Flag, opt *Option) {
+	commandLine().LinkCountToOption(flag, opt)
+}
+
+//Give both sides of each link the larger of their values.
+func (ps *Parser)resolveCountLinks() error {
+	for _, link := range ps.countLinks {
+		level := link.flag.Count
+		if link.opt.Passed {
+			v, err := link.opt.Int()
+			if err != nil {
+				return err
+			}
+			if v > level {
+				level = v
+			}
+		}
+		link.flag.Count = level
+		if level != 0 || link.opt.Passed {
+			link.opt.OptArg = strconv.Itoa(level)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package getopts
+
+import "testing"
+
+//A linked count flag and option resolve to the larger value
+func TestLinkCountToOption(t *testing.T) {
+	resetParams()
+	verbose := NewFlagShort('v', "Increase verbosity")
+	verbosity := NewOptionLong("verbosity", "Verbosity level")
+	LinkCountToOption(verbose, verbosity)
+	_, err := ArgParse([]string{ "test", "-vv", "--verbosity=5" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if verbose.Count != 5 || verbosity.OptArg != "5" {
+		t.Fatalf("Got count %d and level %s, expected 5", verbose.Count, verbosity.OptArg)
+	}
+
+	resetParams()
+	verbose = NewFlagShort('v', "Increase verbosity")
+	verbosity = NewOptionLong("verbosity", "Verbosity level")
+	LinkCountToOption(verbose, verbosity)
+	_, err = ArgParse([]string{ "test", "--verbosity=1", "-vvv" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if verbose.Count != 3 || verbosity.OptArg != "3" {
+		t.Fatalf("Got count %d and level %s, expected 3", verbose.Count, verbosity.OptArg)
+	}
+}
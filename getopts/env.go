@@ -0,0 +1,161 @@
+package getopts
+
+import "os"
+import "strings"
+import "fmt"
+import "io"
+
+const errExportPrefix = "ExportEnv needs a prefix that is a shell variable name, got '%s'"
+
+//Fall back to the environment for every option with a long name.  An option
+//--max-size that is not passed takes its value from <prefix>MAX_SIZE, so with
+//the prefix "MYTOOL_" it reads MYTOOL_MAX_SIZE.  Flags interpret the value
+//like --flag=value.  The command line always wins.
+func (ps *Parser)SetEnvPrefix(prefix string) {
+	ps.envPrefix = prefix
+}
+
+//Fall back to the environment for options of CommandLine.
+func SetEnvPrefix(prefix string) {
+	commandLine().SetEnvPrefix(prefix)
+}
+
+//Option name l in the form used in environment variables:  upper case with
+//hyphens turned into underscores.
+func upperName(l string) string {
+	return strings.ToUpper(strings.ReplaceAll(l, "-", "_"))
+}
+
+//Name of the environment variable for the long option l.
+func (ps *Parser)envName(l string) string {
+	return ps.envPrefix + upperName(l)
+}
+
+//Read the environment variable name when this flag or option is not passed
+//on the command line, as with --flag=value for flags.  This takes precedence
+//over the name given by SetEnvPrefix.
+func (o *option)SetEnv(name string) {
+	o.env = name
+}
+
+//Environment variable consulted for o, or empty if none.
+func (ps *Parser)envVar(o *option) string {
+	if o.env != "" {
+		return o.env
+	}
+	if ps.envPrefix == "" || o.LongOpt == "" {
+		return ""
+	}
+	return ps.envName(o.LongOpt)
+}
+
+//Treat a value from the environment as a list, so MYTOOL_INCLUDE=a:b:c is the
+//same as --include a --include b --include c.  An empty sep means
+//os.PathListSeparator.  If the option is passed on the command line, the
+//environment is not used at all.
+func (o *Option)SetEnvListSep(sep string) {
+	if sep == "" {
+		sep = string(os.PathListSeparator)
+	}
+	o.envListSep = sep
+}
+
+//Add the value of an environment variable to o, splitting it if it is a list.
+func (ps *Parser)addEnvValue(o *Option, value string) error {
+	if o.envListSep == "" {
+		return ps.addOptArg(o, value)
+	}
+	for _, v := range strings.Split(value, o.envListSep) {
+		if err := ps.addOptArg(o, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Assign values from the environment to options that were not passed.
+func (ps *Parser)applyEnv() error {
+	for _, p := range ps.params {
+		o := p.base()
+		name := ps.envVar(o)
+		//A flag turned off on the command line was given too
+		if o.Changed() || name == "" {
+			continue
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		ps.origin = Source{ Kind: SourceEnv, Name: name }
+		if p.opt != nil {
+			if err := ps.addEnvValue(p.opt, value); err != nil {
+				return err
+			}
+		} else {
+			if err := ps.takeString(p.flag, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//Quote s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+//Whether s can be assigned to in a POSIX shell:  letters, digits, and
+//underscores, not starting with a digit.
+func shellName(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for _, c := range s {
+		if !(c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+//Write the parsed values as shell variable assignments, one per line, to be
+//sourced or evaluated by a script.  Each passed option is written as
+//<prefix>_<NAME>='value' and every flag as <prefix>_<NAME>=1 or 0, where NAME
+//is the long option, or short option, in upper case with hyphens turned
+//into underscores.  Sensitive options are written as ***.  The prefix is
+//required, so variables like PATH are never overwritten, and flags and
+//options whose names are not valid in a variable, like -?, are left out.
+func (ps *Parser)ExportEnv(prefix string, w io.Writer) error {
+	if !shellName(prefix) {
+		return fmt.Errorf(errExportPrefix, prefix)
+	}
+	prefix += "_"
+	for _, p := range ps.params {
+		o := p.base()
+		name := prefix + upperName(o.name())
+		if !shellName(name) {
+			continue
+		}
+		var err error
+		if p.opt != nil {
+			if !o.Passed {
+				continue
+			}
+			_, err = fmt.Fprintf(w, "%s=%s\n", name, shellQuote(p.opt.masked(p.opt.OptArg)))
+		} else if o.Passed {
+			_, err = fmt.Fprintf(w, "%s=1\n", name)
+		} else {
+			_, err = fmt.Fprintf(w, "%s=0\n", name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Write the parsed values of CommandLine as shell variable assignments.
+func ExportEnv(prefix string, w io.Writer) error {
+	return commandLine().ExportEnv(prefix, w)
+}
@@ -0,0 +1,147 @@
+package getopts
+
+import "testing"
+import "strings"
+
+//Options not passed fall back to the prefixed environment variable
+func TestEnvPrefix(t *testing.T) {
+	resetParams()
+	size := NewOptionLong("max-size", "Largest size")
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	SetEnvPrefix("MYTOOL_")
+	t.Setenv("MYTOOL_MAX_SIZE", "10")
+	t.Setenv("MYTOOL_VERBOSE", "yes")
+	_, err := ArgParse([]string{ "test" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if size.OptArg != "10" {
+		t.Fatalf("Got %s from environment, expected 10", size.OptArg)
+	}
+	if !verbose.Passed {
+		t.Fatalf("MYTOOL_VERBOSE=yes should set verbose")
+	}
+
+	//Command line wins
+	size.OptArgs = nil
+	_, err = ArgParse([]string{ "test", "--max-size=20" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if size.OptArg != "20" || len(size.OptArgs) != 1 {
+		t.Fatalf("Got %v, expected only the command line value 20", size.OptArgs)
+	}
+
+	//Even when it turns a flag off
+	for _, arg := range []string{ "+v", "--verbose=false" } {
+		ResetValues()
+		_, err = ArgParse([]string{ "test", arg })
+		if err != nil {
+			t.Fatalf("Error %s", err)
+		}
+		if verbose.Passed || verbose.Count > 0 {
+			t.Fatalf("MYTOOL_VERBOSE overrode %s, got count %d", arg, verbose.Count)
+		}
+	}
+}
+
+//An option's own environment variable is used without a prefix and
+//takes precedence over one
+func TestSetEnv(t *testing.T) {
+	resetParams()
+	output := NewOption('o', "output", "Output file")
+	output.SetEnv("MYAPP_OUTPUT")
+	quiet := NewFlagShort('q', "Say less")
+	quiet.SetEnv("MYAPP_QUIET")
+	t.Setenv("MYAPP_OUTPUT", "env.txt")
+	t.Setenv("MYAPP_QUIET", "true")
+	t.Setenv("OTHER_OUTPUT", "prefixed.txt")
+	_, err := ArgParse([]string{ "test" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if output.OptArg != "env.txt" || !quiet.Passed {
+		t.Fatalf("Got %s and %v from environment", output.OptArg, quiet.Passed)
+	}
+
+	ResetValues()
+	SetEnvPrefix("OTHER_")
+	_, err = ArgParse([]string{ "test", "-q" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if output.OptArg != "env.txt" || quiet.Count != 1 {
+		t.Fatalf("Got %s and count %d", output.OptArg, quiet.Count)
+	}
+}
+
+//A list in an environment variable supplies several opt-args
+func TestEnvListSep(t *testing.T) {
+	resetParams()
+	include := NewOption('I', "include", "Include path")
+	include.SetEnvListSep(":")
+	SetEnvPrefix("MYTOOL_")
+	t.Setenv("MYTOOL_INCLUDE", "a:b:c")
+	_, err := ArgParse([]string{ "test" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if len(include.OptArgs) != 3 || include.OptArgs[0] != "a" || include.OptArgs[2] != "c" {
+		t.Fatalf("Got %v expected [a b c]", include.OptArgs)
+	}
+
+	resetParams()
+	include = NewOption('I', "include", "Include path")
+	include.SetEnvListSep(":")
+	SetEnvPrefix("MYTOOL_")
+	_, err = ArgParse([]string{ "test", "-I", "d" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if len(include.OptArgs) != 1 || include.OptArgs[0] != "d" {
+		t.Fatalf("Got %v, expected only the command line value", include.OptArgs)
+	}
+}
+
+//Parsed values are exported as quoted shell assignments
+func TestExportEnv(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	NewFlagLong("dry-run", "Do nothing")
+	NewOptionLong("output-file", "Output file")
+	NewOptionLong("unused", "Not passed")
+	_, err := ArgParse([]string{ "test", "-v", "--output-file", "it's here.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	var b strings.Builder
+	if err := ExportEnv("MYTOOL", &b); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	exp := "MYTOOL_VERBOSE=1\n" +
+		"MYTOOL_DRY_RUN=0\n" +
+		"MYTOOL_OUTPUT_FILE='it'\\''s here.txt'\n"
+	if b.String() != exp {
+		t.Fatalf("Got\n%s\nexpected\n%s", b.String(), exp)
+	}
+}
+
+//ExportEnv never writes a variable that is not a valid shell name
+func TestExportEnvNames(t *testing.T) {
+	resetParams()
+	NewFlagShort('?', "Show help")
+	NewFlagLong("path", "Search the path")
+	var b strings.Builder
+	for _, prefix := range []string{ "", "1TOOL", "MY-TOOL" } {
+		err := ExportEnv(prefix, &b)
+		if err == nil || err.Error() != "ExportEnv needs a prefix that is a shell variable name, got '"+prefix+"'" {
+			t.Fatalf("Got error %v for prefix '%s'", err, prefix)
+		}
+	}
+	if err := ExportEnv("MYTOOL", &b); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if b.String() != "MYTOOL_PATH=0\n" {
+		t.Fatalf("Got\n%s\nexpected only MYTOOL_PATH", b.String())
+	}
+}
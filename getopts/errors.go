@@ -0,0 +1,294 @@
+package getopts
+
+import "fmt"
+import "errors"
+import "strings"
+import "encoding/json"
+
+//Kinds of parse errors, as reported by FormatErrorJSON.
+const(
+	kindUnrecognized = "unrecognized-option"
+	kindNegated = "negated-option"
+	kindAmbiguous = "ambiguous-option"
+	kindMissingArgument = "missing-argument"
+	kindInvalidValue = "invalid-value"
+	kindOther = "error"
+)
+
+//Error about a particular option, which can say what kind of problem it is
+//and which option it is about, to be reported in a structured form.
+type optionError interface {
+	error
+	//Kind of problem and the option as written on the command line
+	describe() (string, string)
+}
+
+//An option was passed that is not registered.
+type ErrUnknownOption struct {
+	//The option without its dashes
+	Name	string
+	//Whether it was passed as a short option
+	Short	bool
+	//Closest registered long option, without its dashes, or empty if none
+	//is close enough
+	Suggestion	string
+}
+
+func (e *ErrUnknownOption)Error() string {
+	if e.Short {
+		return fmt.Sprintf(errUnrecognizedShort, e.Name)
+	}
+	if e.Suggestion != "" {
+		return fmt.Sprintf(errUnrecognizedLong + errSuggestion, e.Name, e.Suggestion)
+	}
+	return fmt.Sprintf(errUnrecognizedLong, e.Name)
+}
+
+func (e *ErrUnknownOption)describe() (string, string) {
+	if e.Short {
+		return kindUnrecognized, "-" + e.Name
+	}
+	return kindUnrecognized, "--" + e.Name
+}
+
+//An abbreviated long option matches more than one long option.
+type ErrAmbiguousOption struct {
+	//The option as passed, without its dashes
+	Name		string
+	//Long options it could be, with their dashes, in registration order
+	Candidates	[]string
+}
+
+func (e *ErrAmbiguousOption)Error() string {
+	return fmt.Sprintf(errAmbiguousLong, e.Name, strings.Join(e.Candidates, ", "))
+}
+
+func (e *ErrAmbiguousOption)describe() (string, string) {
+	return kindAmbiguous, "--" + e.Name
+}
+
+//An option taking an argument was negated, as with +f.
+type ErrNegatedOption struct {
+	//The short option, without the plus
+	Name	string
+}
+
+func (e *ErrNegatedOption)Error() string {
+	return fmt.Sprintf(errTriedToNegateOptArg, e.Name)
+}
+
+func (e *ErrNegatedOption)describe() (string, string) {
+	return kindNegated, "-" + e.Name
+}
+
+//An option was passed without all of its arguments.
+type ErrMissingArgument struct {
+	//The option as written on the command line, like --file
+	Option	string
+	//Number of arguments the option takes
+	Count	int
+}
+
+func (e *ErrMissingArgument)Error() string {
+	if e.Count > 1 {
+		return fmt.Sprintf(errArity, e.Option, e.Count)
+	}
+	return fmt.Sprintf(errMissingArgument, e.Option)
+}
+
+func (e *ErrMissingArgument)describe() (string, string) {
+	return kindMissingArgument, e.Option
+}
+
+//An option was given a value it does not accept, like a word for an
+//integer option.
+type ErrInvalidValue struct {
+	//The option, usually as written on the command line
+	Option	string
+	//The value given, or *** for a sensitive option
+	Value	string
+	msg	string
+	//Error from Value.Set, if that is what rejected the value
+	cause	error
+}
+
+func (e *ErrInvalidValue)Error() string {
+	return e.msg
+}
+
+func (e *ErrInvalidValue)Unwrap() error {
+	return e.cause
+}
+
+func (e *ErrInvalidValue)describe() (string, string) {
+	return kindInvalidValue, e.Option
+}
+
+//An opt-arg was rejected by the Validate function of its option.
+type ErrValidation struct {
+	//The option, usually as written on the command line
+	Option	string
+	//The opt-arg, or *** for a sensitive option
+	Value	string
+	//Position of the argument holding the opt-arg, counted as in
+	//Rest.Index, or 0 if the opt-arg came from the environment or a
+	//config file
+	Index	int
+	//Error returned by Validate.  For a sensitive option its text may
+	//hold the opt-arg, which Error masks.
+	Err	error
+	//Text of Err with a sensitive opt-arg masked, or empty to use Err
+	reason	string
+}
+
+func (e *ErrValidation)Error() string {
+	var reason any = e.Err
+	if e.reason != "" {
+		reason = e.reason
+	}
+	if e.Index == 0 {
+		return fmt.Sprintf(errValidationElsewhere, e.Value, e.Option, reason)
+	}
+	return fmt.Sprintf(errValidation, e.Value, e.Option, e.Index, reason)
+}
+
+func (e *ErrValidation)Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrValidation)describe() (string, string) {
+	return kindInvalidValue, e.Option
+}
+
+//Every error found by a parse that collects errors, in the order they
+//were found.  errors.Is and errors.As look through all of them.
+type ErrMultiple struct {
+	Errors	[]error
+}
+
+//Messages of every error, one per line.
+func (e *ErrMultiple)Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (e *ErrMultiple)Unwrap() []error {
+	return e.Errors
+}
+
+func unrecognizedShort(s rune) error {
+	return &ErrUnknownOption{
+		Name:	string(s),
+		Short:	true,
+	}
+}
+
+func (ps *Parser)unrecognizedLong(l string) error {
+	return &ErrUnknownOption{
+		Name:		l,
+		Suggestion:	ps.suggest(l),
+	}
+}
+
+//Number of single character insertions, deletions, and substitutions
+//needed to turn a into b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b) + 1)
+	cur := make([]int, len(b) + 1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j] + 1, cur[j-1] + 1, prev[j-1] + cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+//Suggest long options for unrecognized ones within distance edits, so
+//--verbos suggests --verbose.  0 turns suggestions off.  The default is 2.
+func (ps *Parser)SetSuggestDistance(distance int) {
+	ps.suggestDistance = distance
+}
+
+//Suggest long options of CommandLine within distance edits.
+func SetSuggestDistance(distance int) {
+	commandLine().SetSuggestDistance(distance)
+}
+
+//Registered long option closest to l, if it is close enough.  The first
+//registered wins a tie.
+func (ps *Parser)suggest(l string) string {
+	best := ""
+	bestDistance := ps.suggestDistance + 1
+	for _, p := range ps.params {
+		for _, long := range p.base().longs() {
+			if d := editDistance(l, long); d < bestDistance {
+				best = long
+				bestDistance = d
+			}
+		}
+	}
+	return best
+}
+
+func negatedOption(s rune) error {
+	return &ErrNegatedOption{
+		Name:	string(s),
+	}
+}
+
+func missingArgument(option string) error {
+	return &ErrMissingArgument{
+		Option:	option,
+		Count:	1,
+	}
+}
+
+//Error for value of option, with the message format applied to a.
+func invalidValue(option, value, format string, a ...any) error {
+	return &ErrInvalidValue{
+		Option:	option,
+		Value:	value,
+		msg:	fmt.Sprintf(format, a...),
+	}
+}
+
+//JSON form of an error.
+type jsonError struct {
+	Kind		string	`json:"kind"`
+	Option		string	`json:"option,omitempty"`
+	Message		string	`json:"message"`
+	Suggestion	string	`json:"suggestion,omitempty"`
+}
+
+//Render an error from parsing as a JSON object for programs driving the
+//tool, like {"kind":"unrecognized-option","option":"-x","message":"..."}.
+//Errors that are not about a particular option have kind "error" and
+//no option.
+func FormatErrorJSON(err error) string {
+	out := jsonError{
+		Kind:		kindOther,
+		Message:	err.Error(),
+	}
+	var e optionError
+	if errors.As(err, &e) {
+		out.Kind, out.Option = e.describe()
+	}
+	var unknown *ErrUnknownOption
+	if errors.As(err, &unknown) && unknown.Suggestion != "" {
+		out.Suggestion = "--" + unknown.Suggestion
+	}
+	b, _ := json.Marshal(out)
+	return string(b)
+}
@@ -0,0 +1,181 @@
+package getopts
+
+import "testing"
+import "errors"
+import "strings"
+import "fmt"
+
+//Unrecognized options render as JSON with their kind and name
+func TestFormatErrorJSON(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	_, err := ArgParse([]string{ "test", "--verbos" })
+	if err == nil {
+		t.Fatalf("--verbos should be an error")
+	}
+	exp := `{"kind":"unrecognized-option","option":"--verbos",` +
+		`"message":"Unrecognized long option:  verbos, did you mean --verbose?","suggestion":"--verbose"}`
+	if got := FormatErrorJSON(err); got != exp {
+		t.Fatalf("Got %s expected %s", got, exp)
+	}
+
+	exp = `{"kind":"error","message":"failed"}`
+	if got := FormatErrorJSON(errors.New("failed")); got != exp {
+		t.Fatalf("Got %s expected %s", got, exp)
+	}
+}
+
+//Parse errors can be told apart with errors.As
+func TestErrorTypes(t *testing.T) {
+	resetParams()
+	NewOption('f', "file", "File to read")
+	point := NewOptionLong("point", "Coordinates")
+	point.SetArity(2)
+	jobs := NewIntOption('j', "jobs", "Number of jobs")
+	jobs.SetSensitive(true)
+
+	_, err := ArgParse([]string{ "test", "-x" })
+	var unknown *ErrUnknownOption
+	if !errors.As(err, &unknown) || unknown.Name != "x" || !unknown.Short {
+		t.Fatalf("Got %v, expected unknown -x", err)
+	}
+	if err.Error() != "Unrecognized short option:  x" {
+		t.Fatalf("Got message %s", err)
+	}
+
+	_, err = ArgParse([]string{ "test", "+f" })
+	var negated *ErrNegatedOption
+	if !errors.As(err, &negated) || negated.Name != "f" {
+		t.Fatalf("Got %v, expected negated -f", err)
+	}
+
+	_, err = ArgParse([]string{ "test", "--point", "1" })
+	var missing *ErrMissingArgument
+	if !errors.As(err, &missing) || missing.Option != "--point" || missing.Count != 2 {
+		t.Fatalf("Got %v, expected missing arguments to --point", err)
+	}
+	for _, arg := range []string{ "-f", "--file" } {
+		_, err = ArgParse([]string{ "test", "x.txt", arg })
+		if !errors.As(err, &missing) || missing.Option != "--file" || missing.Count != 1 {
+			t.Fatalf("Got %v, expected a missing argument to %s", err, arg)
+		}
+		if err.Error() != "Missing argument to option:  --file" {
+			t.Fatalf("Got message %s", err)
+		}
+	}
+
+	_, err = ArgParse([]string{ "test", "--jobs=secret" })
+	var invalid *ErrInvalidValue
+	if !errors.As(err, &invalid) || invalid.Option != "--jobs" || invalid.Value != "***" {
+		t.Fatalf("Got %v, expected invalid value for --jobs", err)
+	}
+}
+
+//Unknown long options suggest the closest registered one within the
+//distance, if any
+func TestSuggestion(t *testing.T) {
+	resetParams()
+	NewFlagLong("verbose", "Increase verbosity")
+	NewOptionLong("version", "Version to use")
+	cases := []struct {
+		arg	string
+		exp	string
+	}{
+		{ "--verbos", "Unrecognized long option:  verbos, did you mean --verbose?" },
+		{ "--vresion=2", "Unrecognized long option:  vresion, did you mean --version?" },
+		{ "--quiet", "Unrecognized long option:  quiet" },
+	}
+	for _, c := range cases {
+		_, err := ArgParse([]string{ "test", c.arg })
+		if err == nil || err.Error() != c.exp {
+			t.Fatalf("Got error %v, expected '%s'", err, c.exp)
+		}
+	}
+	SetSuggestDistance(0)
+	_, err := ArgParse([]string{ "test", "--verbos" })
+	if err == nil || err.Error() != "Unrecognized long option:  verbos" {
+		t.Fatalf("Got error %v, expected no suggestion", err)
+	}
+}
+
+//Collecting errors reports every mistake, parsing what it can around them
+func TestCollectErrors(t *testing.T) {
+	resetParams()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	NewOption('f', "file", "File to read")
+	SetCollectErrors(true)
+
+	rest, err := ArgParse([]string{ "test", "-x", "--verbose=maybe", "+f", "-v", "a.txt", "--quiet" })
+	var multiple *ErrMultiple
+	if !errors.As(err, &multiple) {
+		t.Fatalf("Got error %v, expected ErrMultiple", err)
+	}
+	if len(multiple.Errors) != 4 {
+		t.Fatalf("Got %d errors, expected 4:\n%s", len(multiple.Errors), err)
+	}
+	var unknown *ErrUnknownOption
+	if !errors.As(err, &unknown) || unknown.Name != "x" {
+		t.Fatalf("Expected the first unknown option to be x, got %v", unknown)
+	}
+	var negated *ErrNegatedOption
+	if !errors.As(err, &negated) {
+		t.Fatalf("Expected an ErrNegatedOption among %s", err)
+	}
+	if !verbose.Passed || len(rest) != 1 || rest[0].Argument != "a.txt" {
+		t.Fatalf("Arguments around the errors were not parsed")
+	}
+
+	SetCollectErrors(false)
+	_, err = ArgParse([]string{ "test", "-x", "--quiet" })
+	if errors.As(err, &multiple) {
+		t.Fatalf("Got ErrMultiple when not collecting errors")
+	}
+}
+
+//Validate rejects opt-args with an error naming the option and position
+func TestValidate(t *testing.T) {
+	resetParams()
+	errUpper := errors.New("must be lower case")
+	name := NewOption('n', "name", "Name")
+	name.Validate = func(arg string) error {
+		if strings.ToLower(arg) != arg {
+			return errUpper
+		}
+		return nil
+	}
+	_, err := ArgParse([]string{ "test", "-n", "ok", "--name", "Bad" })
+	exp := "Invalid value 'Bad' for --name at argument 4:  must be lower case"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v, expected '%s'", err, exp)
+	}
+	var validation *ErrValidation
+	if !errors.As(err, &validation) || validation.Index != 4 || !errors.Is(err, errUpper) {
+		t.Fatalf("Got error %#v, expected an ErrValidation at 4", err)
+	}
+	if len(name.OptArgs) != 1 {
+		t.Fatalf("The rejected opt-arg should not be stored, got %v", name.OptArgs)
+	}
+	t.Setenv("TEST_NAME", "Env")
+	SetEnvPrefix("TEST_")
+	ResetValues()
+	_, err = ArgParse([]string{ "test" })
+	exp = "Invalid value 'Env' for --name:  must be lower case"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v, expected '%s'", err, exp)
+	}
+}
+
+//A validator repeating a sensitive value does not leak it
+func TestValidationSensitive(t *testing.T) {
+	resetParams()
+	token := NewOptionLong("token", "API token")
+	token.SetSensitive(true)
+	token.Validate = func(arg string) error {
+		return fmt.Errorf("%s is not a token", arg)
+	}
+	_, err := ArgParse([]string{ "test", "--token=hunter2" })
+	exp := "Invalid value '***' for --token at argument 1:  *** is not a token"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v, expected '%s'", err, exp)
+	}
+}
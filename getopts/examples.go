@@ -0,0 +1,68 @@
+package getopts
+
+import "fmt"
+import "io"
+
+const(
+	errExample = "Example %q:  %w"
+	errExampleEmpty = "Example %q has no program name"
+)
+
+//Show cmdline, written with the program name like "mytool -v a.txt", and
+//description of what it does in the examples of help and the EXAMPLES
+//section of man pages.  CheckExamples parses every example, so a
+//test can catch examples that no longer work.
+func (ps *Parser)AddExample(cmdline, description string) {
+	ps.helpExamples = append(ps.helpExamples, ManExample{
+		Description:	description,
+		Command:	cmdline,
+	})
+}
+
+//Add an example to help for CommandLine.
+func AddExample(cmdline, description string) {
+	commandLine().AddExample(cmdline, description)
+}
+
+//Parse the command of every example, split into words like ParseLine with
+//the first word taken as the program name, and return an error naming the
+//first that fails.  Callbacks run and bound variables are set as for any
+//parse, so call it from a test; the values of flags and options are reset
+//after each example.
+func (ps *Parser)CheckExamples() error {
+	for _, example := range ps.helpExamples {
+		words, err := tokenizeLine(example.Command)
+		if err != nil {
+			return fmt.Errorf(errExample, example.Command, err)
+		}
+		if len(words) == 0 {
+			return fmt.Errorf(errExampleEmpty, example.Command)
+		}
+		_, err = ps.parseWordList(words[1:])
+		ps.ResetValues()
+		if err != nil {
+			return fmt.Errorf(errExample, example.Command, err)
+		}
+	}
+	return nil
+}
+
+//Parse every example of CommandLine.
+func CheckExamples() error {
+	return commandLine().CheckExamples()
+}
+
+//Write the examples of help after the options, each description followed
+//by its command, indented.
+func (ps *Parser)writeExamples(w io.Writer) {
+	if len(ps.helpExamples) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nExamples:\n")
+	for _, example := range ps.helpExamples {
+		if example.Description != "" {
+			fmt.Fprintf(w, "  %s\n", example.Description)
+		}
+		fmt.Fprintf(w, "    %s\n", example.Command)
+	}
+}
@@ -0,0 +1,76 @@
+package getopts
+
+import "flag"
+import "strconv"
+import "unicode/utf8"
+
+//Implemented by flag.Value types that need no argument, like those made by
+//flag.Bool.
+type boolFlag interface {
+	IsBoolFlag() bool
+}
+
+//Short and long option for the flag.FlagSet flag name:  a name of one
+//character is a short option, any other is a long option.
+func flagSetNames(name string) (rune, string) {
+	if utf8.RuneCountInString(name) == 1 {
+		s, _ := utf8.DecodeRuneInString(name)
+		return s, ""
+	}
+	return 0, name
+}
+
+//Register every flag defined on fs, so a program using the flag package can
+//take up clumps, --long options, and +negation without rewriting its flags.
+//A name of one character becomes a short option and any other a long
+//option.  Usage becomes the help and DefValue the default.  Boolean flags
+//become flags; the rest become options whose opt-args are passed to the
+//flag's Value, so the variables the program already reads are set.  If a
+//name is already used, nothing is registered and an error is returned.
+func (ps *Parser)FromFlagSet(fs *flag.FlagSet) error {
+	specs := make([]Spec, 0)
+	values := make([]flag.Value, 0)
+	fs.VisitAll(func(f *flag.Flag) {
+		s, l := flagSetNames(f.Name)
+		spec := Spec{
+			Short:	s,
+			Long:	l,
+			Help:	f.Usage,
+			Type:	SpecOption,
+			Default:	f.DefValue,
+		}
+		if b, ok := f.Value.(boolFlag); ok && b.IsBoolFlag() {
+			spec.Type = SpecFlag
+		}
+		specs = append(specs, spec)
+		values = append(values, f.Value)
+	})
+	if err := ps.checkSpecs(specs); err != nil {
+		return err
+	}
+	for i, spec := range specs {
+		value := values[i]
+		def := spec.Default
+		ps.unbound = append(ps.unbound, func() {
+			value.Set(def)
+		})
+		if spec.Type == SpecFlag {
+			f := ps.newFlag(spec.Short, spec.Long, spec.Help)
+			ps.bound = append(ps.bound, func() {
+				if f.Passed || f.Count != 0 {
+					value.Set(strconv.FormatBool(f.Passed))
+				}
+			})
+			continue
+		}
+		opt := ps.NewValueOption(spec.Short, spec.Long, spec.Help, value)
+		opt.Default = spec.Default
+	}
+	return nil
+}
+
+//Register every flag defined on fs with CommandLine.
+func FromFlagSet(fs *flag.FlagSet) error {
+	defer syncCommandLine()
+	return commandLine().FromFlagSet(fs)
+}
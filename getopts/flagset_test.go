@@ -0,0 +1,40 @@
+package getopts
+
+import "testing"
+import "flag"
+import "strings"
+import "time"
+
+//Flags of a flag.FlagSet are parsed by getopts into the program's variables
+func TestFromFlagSet(t *testing.T) {
+	resetParams()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	verbose := fs.Bool("v", false, "Increase verbosity")
+	dryRun := fs.Bool("dry-run", true, "Show what would be done")
+	name := fs.String("name", "anon", "Name to use")
+	timeout := fs.Duration("t", time.Second, "Time to wait")
+	if err := FromFlagSet(fs); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	_, err := ArgParse([]string{ "test", "-vt", "5s", "--dry-run=false", "--name", "bob" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if !*verbose || *dryRun || *name != "bob" || *timeout != 5 * time.Second {
+		t.Fatalf("Got %v %v %s %v", *verbose, *dryRun, *name, *timeout)
+	}
+
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	if !strings.Contains(b.String(), "--name    Name to use (default: anon)\n") {
+		t.Fatalf("Got help %s", b.String())
+	}
+
+	_, err = ArgParse([]string{ "test", "-t", "soon" })
+	if err == nil || !strings.HasPrefix(err.Error(), "Argument to option -t is not valid:  soon") {
+		t.Fatalf("Got error %v, expected -t to reject soon", err)
+	}
+	if err := FromFlagSet(fs); err == nil {
+		t.Fatalf("Importing the same flags twice should be an error")
+	}
+}
@@ -0,0 +1,72 @@
+package getopts
+
+import "fmt"
+import "reflect"
+import "strings"
+import "unicode/utf8"
+
+const(
+	errGetUnknown = "No flag or option named %s"
+	errGetType = "%s can be read as %s, not %s"
+)
+
+//The flag or option named name:  a long option, or a single character
+//short option, with or without its dashes.
+func (ps *Parser)lookupName(name string) (parameter, bool) {
+	name = strings.TrimLeft(name, "-")
+	if utf8.RuneCountInString(name) == 1 {
+		s, _ := utf8.DecodeRuneInString(name)
+		if p, ok := ps.paramsByShort[s]; ok {
+			return p, true
+		}
+	}
+	p, ok := ps.paramsByLong[name]
+	return p, ok
+}
+
+//Values p can be read as, most specific first:  Passed and Count for
+//flags, and for options the converted value of a typed option, OptArg,
+//and OptArgs.
+func getValues(p parameter) []any {
+	if p.flag != nil {
+		return []any{ p.flag.Passed, p.flag.Count }
+	}
+	values := make([]any, 0, 3)
+	if p.opt.typed != nil {
+		values = append(values, p.opt.typed())
+	}
+	return append(values, p.opt.OptArg, p.opt.OptArgs)
+}
+
+//Value of the flag or option of ps named name, a long option or a short
+//option, as a T.  See Get.
+func GetFrom[T any](ps *Parser, name string) (T, error) {
+	var v T
+	p, ok := ps.lookupName(name)
+	if !ok {
+		return v, fmt.Errorf(errGetUnknown, name)
+	}
+	values := getValues(p)
+	for _, value := range values {
+		if v, ok := value.(T); ok {
+			return v, nil
+		}
+	}
+	types := make([]string, len(values))
+	for i, value := range values {
+		types[i] = fmt.Sprintf("%T", value)
+	}
+	return v, fmt.Errorf(errGetType, optionNames(*p.base()), strings.Join(types, ", "),
+		reflect.TypeFor[T]())
+}
+
+//Value of the flag or option of CommandLine named name, like "jobs" or
+//"j", as a T, for programs that look values up by name rather than holding
+//on to what the New functions return.  A flag is a bool, Passed, or an int,
+//Count.  An option is a string, OptArg, or a []string, OptArgs, and a typed
+//option is also the type of its Value, so Get[int]("jobs") reads an
+//IntOption, and a HostPortOption is a HostPort.  Returns an error if there is no such flag or option, or it
+//cannot be read as a T.
+func Get[T any](name string) (T, error) {
+	return GetFrom[T](commandLine(), name)
+}
@@ -0,0 +1,51 @@
+package getopts
+
+import "testing"
+import "time"
+
+//Values are found by long or short name and read as the type asked for
+func TestGet(t *testing.T) {
+	resetParams()
+	NewIntOption('j', "jobs", "Number of jobs")
+	NewDurationOption(0, "timeout", "Time to wait")
+	NewFlag('v', "verbose", "Increase verbosity")
+	NewOption('I', "include", "Directory to search")
+	NewHostPortOption(0, "listen", "Address to listen on")
+	_, err := ArgParse([]string{ "test", "-j4", "-vv", "--timeout=5s", "-I", "a", "-Ib",
+		"--listen=[::1]:8080" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	jobs, err := Get[int]("jobs")
+	if err != nil || jobs != 4 {
+		t.Fatalf("Got %d and error %v, expected 4", jobs, err)
+	}
+	if arg, err := Get[string]("-j"); err != nil || arg != "4" {
+		t.Fatalf("Got %s and error %v, expected 4", arg, err)
+	}
+	if timeout, err := Get[time.Duration]("--timeout"); err != nil || timeout != 5 * time.Second {
+		t.Fatalf("Got %v and error %v, expected 5s", timeout, err)
+	}
+	if verbose, err := Get[bool]("v"); err != nil || !verbose {
+		t.Fatalf("Got %v and error %v, expected true", verbose, err)
+	}
+	if count, err := Get[int]("verbose"); err != nil || count != 2 {
+		t.Fatalf("Got %d and error %v, expected 2", count, err)
+	}
+	if dirs, err := Get[[]string]("include"); err != nil || len(dirs) != 2 {
+		t.Fatalf("Got %v and error %v, expected a and b", dirs, err)
+	}
+	listen, err := Get[HostPort]("listen")
+	if err != nil || listen != (HostPort{ Host: "::1", Port: 8080 }) {
+		t.Fatalf("Got %v and error %v, expected ::1 and 8080", listen, err)
+	}
+	_, err = Get[float64]("jobs")
+	exp := "-j/--jobs can be read as int, string, []string, not float64"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v, expected '%s'", err, exp)
+	}
+	_, err = Get[int]("missing")
+	if err == nil || err.Error() != "No flag or option named missing" {
+		t.Fatalf("Got error %v", err)
+	}
+}
@@ -0,0 +1,540 @@
+package getopts
+
+import "os"
+import "fmt"
+import "io"
+import "errors"
+import "sort"
+import "slices"
+import "strings"
+import "strconv"
+import "unicode/utf8"
+import "path/filepath"
+
+//Order of options in help output.
+type HelpSort int
+
+const(
+	//Options appear in the order they were registered
+	HelpSortRegistration HelpSort = iota
+	//Options are sorted by long option, or short option if there is
+	//no long option
+	HelpSortAlphabetical
+)
+
+//Describes an operand expected after the options, for the usage synopsis.
+type OperandSpec struct {
+	//Placeholder shown in the synopsis, like SRC
+	Name		string
+	//Whether the operand can be repeated, shown as SRC...
+	Variadic	bool
+	//Whether the operand can be left out, shown as [SRC]
+	Optional	bool
+}
+
+//Declare the operands shown in the usage synopsis at the top of help.
+//Parsing is not affected.
+func (ps *Parser)SetOperandSpec(spec []OperandSpec) {
+	ps.operandSpec = spec
+}
+
+//Declare the operands of CommandLine.
+func SetOperandSpec(spec []OperandSpec) {
+	commandLine().SetOperandSpec(spec)
+}
+
+//Show every long option given with AddLongName in help, like
+//--color, --colour, instead of just the first.
+func (ps *Parser)SetHelpShowLongNames(show bool) {
+	ps.helpShowLongNames = show
+}
+
+//Show every long option of CommandLine in help.
+func SetHelpShowLongNames(show bool) {
+	commandLine().SetHelpShowLongNames(show)
+}
+
+//Choose the order of options in help output.
+func (ps *Parser)SetHelpSort(mode HelpSort) {
+	ps.helpSort = mode
+}
+
+//Choose the order of options in help output for CommandLine.
+func SetHelpSort(mode HelpSort) {
+	commandLine().SetHelpSort(mode)
+}
+
+//Registered parameters in the order they appear in help.
+func (ps *Parser)helpOrder() []parameter {
+	ordered := make([]parameter, len(ps.params))
+	copy(ordered, ps.params)
+	if ps.helpSort == HelpSortAlphabetical {
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return ordered[i].base().name() < ordered[j].base().name()
+		})
+	}
+	return ordered
+}
+
+//Options listed together in help under a title.
+type helpGroup struct {
+	//Empty for the options in no section, which come first
+	title	string
+	params	[]parameter
+}
+
+//List params under the heading title in help and man pages, like
+//"Connection options".  Sections appear in the order they are first named,
+//after the options in no section, unless SetHelpSectionOrder says
+//otherwise.  Panics if a param is not registered.
+func (ps *Parser)SetHelpSection(title string, params ...Param) {
+	for _, p := range params {
+		o := p.common()
+		if _, ok := ps.paramFor(o); !ok {
+			panic("Adding an option that is not registered to a help section")
+		}
+		o.section = title
+	}
+	for _, section := range ps.helpSections {
+		if section == title {
+			return
+		}
+	}
+	ps.helpSections = append(ps.helpSections, title)
+}
+
+//List params of CommandLine under the heading title in help.
+func SetHelpSection(title string, params ...Param) {
+	commandLine().SetHelpSection(title, params...)
+}
+
+//Show the help sections named in titles first, in that order, followed by
+//any others in the order they were first named.
+func (ps *Parser)SetHelpSectionOrder(titles ...string) {
+	ordered := make([]string, 0, len(ps.helpSections))
+	ordered = append(ordered, titles...)
+	for _, section := range ps.helpSections {
+		if !slices.Contains(titles, section) {
+			ordered = append(ordered, section)
+		}
+	}
+	ps.helpSections = ordered
+}
+
+//Order the help sections of CommandLine.
+func SetHelpSectionOrder(titles ...string) {
+	commandLine().SetHelpSectionOrder(titles...)
+}
+
+//Options in help order, split into the options in no section followed by
+//each help section.  Empty groups are left out.
+func (ps *Parser)helpGroups() []helpGroup {
+	ordered := ps.helpOrder()
+	groups := make([]helpGroup, 0, len(ps.helpSections) + 1)
+	for _, title := range append([]string{ "" }, ps.helpSections...) {
+		group := helpGroup{ title: title }
+		for _, p := range ordered {
+			if p.base().section == title {
+				group.params = append(group.params, p)
+			}
+		}
+		if len(group.params) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+//Widest left column in help.  Longer option names put their help on the
+//next line.
+const maxHelpColumn = 30
+
+//Width of help when neither SetHelpWidth, the terminal, nor $COLUMNS
+//gives one.
+const defaultHelpWidth = 80
+
+//Options as shown in the left column of help, like -v/--verbose, followed
+//by the Metavar of an option, like --output FILE, or else the choices of a
+//choice option, like --format {json|yaml}.  Flags show
+//--[no-]verbose if they can be negated that way, and options with an
+//optional argument show its implicit value, like --color[=auto].  Extra
+//long options follow the first if SetHelpShowLongNames is on.
+func (ps *Parser)helpLabel(p parameter) string {
+	return ps.helpNames(p) + helpArg(p)
+}
+
+//The names part of the help label of p.
+func (ps *Parser)helpNames(p parameter) string {
+	label := optionNames(*p.base())
+	if p.flag != nil && ps.negateWithNo && p.flag.LongOpt != "" {
+		label = strings.Replace(label, "--", "--[no-]", 1)
+	}
+	if ps.helpShowLongNames {
+		for _, l := range p.base().longNames {
+			label += ", --" + l
+		}
+	}
+	return label
+}
+
+//The opt-arg part of the help label of p, or empty.
+func helpArg(p parameter) string {
+	if p.opt != nil && p.opt.optionalArg {
+		return "[=" + p.opt.implicitArg + "]"
+	} else if p.opt != nil && p.opt.Metavar != "" {
+		return " " + p.opt.Metavar
+	} else if p.opt != nil && len(p.opt.choices) > 0 {
+		return " {" + strings.Join(p.opt.choices, "|") + "}"
+	}
+	return ""
+}
+
+//Names of opt as written in help.
+func optionNames(opt option) string {
+	if opt.ShortOpt == 0 {
+		//Only long option.  If we have an option with neither,
+		//that's a bug
+		if opt.LongOpt == "" {
+			panic("Long and short options are both empty")
+		}
+		return "--" + opt.LongOpt
+	} else if opt.LongOpt == "" {
+		//Have only short opt
+		return "-" + string(opt.ShortOpt)
+	}
+	//Long and short opt
+	return fmt.Sprintf("-%c/--%s", opt.ShortOpt, opt.LongOpt)
+}
+
+//Split text into lines of at most width characters, breaking at white
+//space.  A word longer than width gets a line of its own.
+func wrapText(text string, width int) []string {
+	lines := make([]string, 0)
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line == "" {
+			line = word
+		} else if len(line) + 1 + len(word) <= width {
+			line += " " + word
+		} else {
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+//Help text for p, followed by how to write a list for a list option, by
+//its default if it has one, and by whether it is deprecated.
+func helpText(p parameter) string {
+	return p.base().Help + helpNotes(p, true)
+}
+
+//Notes added after the help text of p:  how a list is separated, the
+//forms of opt-arg accepted, the default if withDefault is set, and whether
+//p is deprecated.
+func helpNotes(p parameter, withDefault bool) string {
+	text := ""
+	if p.opt != nil && p.opt.splitOn != "" && p.opt.splitEscapes {
+		text += fmt.Sprintf(" (separated by '%s', \\%s for a literal '%s')",
+			p.opt.splitOn, p.opt.splitOn, p.opt.splitOn)
+	}
+	if p.opt != nil && p.opt.syntax != "" {
+		text += fmt.Sprintf(" (%s)", p.opt.syntax)
+	}
+	if withDefault && p.opt != nil && p.opt.shownDefault() != "" {
+		text += fmt.Sprintf(" (default: %s)", p.opt.shownDefault())
+	}
+	if o := p.base(); o.deprecated && o.deprecation != "" {
+		text += fmt.Sprintf(" (deprecated, %s)", o.deprecation)
+	} else if o.deprecated {
+		text += " (deprecated)"
+	}
+	return text
+}
+
+//Write the help for p, with its names padded to column and its help text
+//wrapped to fit in width.  If color is set, the names are bold and the
+//opt-arg dim.
+func (ps *Parser)showOptionHelp(w io.Writer, p parameter, column, width int, color bool) {
+	names, arg := ps.helpNames(p), helpArg(p)
+	label := names + arg
+	n := utf8.RuneCountInString(label)
+	if color {
+		label = ansiBold + names + ansiReset
+		if arg != "" {
+			label += ansiDim + arg + ansiReset
+		}
+	}
+	indent := strings.Repeat(" ", column + 1)
+	lines := wrapText(helpText(p), width - column - 1)
+	if n > column {
+		fmt.Fprintln(w, label)
+		fmt.Fprintf(w, "%s%s\n", indent, lines[0])
+	} else {
+		fmt.Fprintf(w, "%s%s %s\n", label, strings.Repeat(" ", column - n), lines[0])
+	}
+	for _, line := range lines[1:] {
+		fmt.Fprintf(w, "%s%s\n", indent, line)
+	}
+}
+
+//Send help to w instead of standard output, like standard error or a pager.
+func (ps *Parser)SetHelpOutput(w io.Writer) {
+	ps.helpOutput = w
+}
+
+//Send help for CommandLine to w.
+func SetHelpOutput(w io.Writer) {
+	commandLine().SetHelpOutput(w)
+}
+
+//Wrap help to width columns.  0, the default, uses the width of the
+//terminal help is written to, or $COLUMNS if it is not a terminal, or 80
+//if neither is known.
+func (ps *Parser)SetHelpWidth(width int) {
+	ps.helpWidth = width
+}
+
+//Wrap help for CommandLine to width columns.
+func SetHelpWidth(width int) {
+	commandLine().SetHelpWidth(width)
+}
+
+//Width to wrap help written to w to.
+func (ps *Parser)terminalWidth(w io.Writer) int {
+	if ps.helpWidth > 0 {
+		return ps.helpWidth
+	}
+	if f, ok := w.(*os.File); ok {
+		if n, ok := terminalSize(f); ok && n > 0 {
+			return n
+		}
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultHelpWidth
+}
+
+//Write help to the writer given to SetHelpOutput, or standard output.
+//Options are listed with their names in a left column and their help
+//text wrapped to the terminal width in a right column.
+func (ps *Parser)ShowHelp() {
+	ps.writeHelp(ps.helpWriter())
+}
+
+//Writer given to SetHelpOutput, or standard output.
+func (ps *Parser)helpWriter() io.Writer {
+	if ps.helpOutput != nil {
+		return ps.helpOutput
+	}
+	return os.Stdout
+}
+
+//Write help for CommandLine.
+func ShowHelp() {
+	commandLine().ShowHelp()
+}
+
+//Returned by parsing after -h or --help printed help.
+var ErrHelpRequested = errors.New("Help requested")
+
+//Returned by parsing after --version printed the version.
+var ErrVersionRequested = errors.New("Version requested")
+
+//Register -h and --help, which print help with ShowHelp and make parsing
+//return ErrHelpRequested.  Like any flag set with SetBypassRequired, they
+//skip the checks for required options.  Panics if either name is taken.
+func (ps *Parser)SetAutoHelp() {
+	ps.helpFlag = ps.NewFlag('h', "help", "Show this help")
+	ps.helpFlag.SetBypassRequired(true)
+}
+
+//Register -h and --help with CommandLine.
+func SetAutoHelp() {
+	defer syncCommandLine()
+	commandLine().SetAutoHelp()
+}
+
+//Register --version, which prints the program name and version to the help
+//output and makes parsing return ErrVersionRequested.
+func (ps *Parser)SetVersion(version string) {
+	ps.version = version
+	ps.versionFlag = ps.NewFlagLong("version", "Show the version")
+	ps.versionFlag.SetBypassRequired(true)
+}
+
+//Register --version with CommandLine.
+func SetVersion(version string) {
+	defer syncCommandLine()
+	commandLine().SetVersion(version)
+}
+
+//Print help or the version if their flags were passed.  Help wins if both
+//were.
+func (ps *Parser)handleAutoFlags() error {
+	if ps.helpFlag != nil && ps.helpFlag.Passed {
+		ps.ShowHelp()
+		return ErrHelpRequested
+	}
+	if ps.versionFlag != nil && ps.versionFlag.Passed {
+		fmt.Fprintf(ps.helpWriter(), "%s %s\n", ps.programName(), ps.version)
+		return ErrVersionRequested
+	}
+	return nil
+}
+
+//Name of the program for help and error messages:  the name given to
+//SetProgram, or else argv[0] of the last parse, or of os.Args before
+//parsing.  Empty if neither is available.
+func (ps *Parser)programName() string {
+	if ps.program != "" {
+		return ps.program
+	}
+	if ps.argvName != "" {
+		return ps.argvName
+	}
+	if len(os.Args) == 0 || os.Args[0] == "" {
+		return ""
+	}
+	return filepath.Base(os.Args[0])
+}
+
+//Name the program and describe it in a line, for the top of help and the
+//NAME section of man pages.  name replaces argv[0] in help and error
+//messages; leave it empty to keep argv[0].
+func (ps *Parser)SetProgram(name, summary string) {
+	ps.program = name
+	ps.summary = summary
+}
+
+//Name and describe the program of CommandLine.
+func SetProgram(name, summary string) {
+	commandLine().SetProgram(name, summary)
+}
+
+//Replace the usage line built from the required options and operand spec
+//by lines, each written without the program name, like
+//"[options] SRC... DST".  Help shows the first after "Usage:" and the rest
+//after "or:".
+func (ps *Parser)SetSynopsis(lines ...string) {
+	ps.synopsisLines = lines
+}
+
+//Replace the usage lines of CommandLine.
+func SetSynopsis(lines ...string) {
+	commandLine().SetSynopsis(lines...)
+}
+
+//Show text after the options in help and in the NOTES section of man
+//pages, like where to report bugs.
+func (ps *Parser)SetEpilog(text string) {
+	ps.epilog = text
+}
+
+//Show text after the options in help for CommandLine.
+func SetEpilog(text string) {
+	commandLine().SetEpilog(text)
+}
+
+//Usage lines at the top of help:  those given to SetSynopsis, or the one
+//built from the operand spec, or none.
+func (ps *Parser)usageLines() []string {
+	if len(ps.synopsisLines) == 0 {
+		if len(ps.operandSpec) > 0 {
+			return []string{ ps.synopsis() }
+		}
+		return nil
+	}
+	lines := make([]string, len(ps.synopsisLines))
+	for i, line := range ps.synopsisLines {
+		prefix := "Usage:"
+		if i > 0 {
+			prefix = "   or:"
+		}
+		lines[i] = fmt.Sprintf("%s %s %s", prefix, ps.programName(), line)
+	}
+	return lines
+}
+
+//Usage line built from the required options and the operand spec, like
+//"Usage: mytool [options] --output FILE SRC... DST"
+func (ps *Parser)synopsis() string {
+	return fmt.Sprintf("Usage: %s [options]%s%s", ps.programName(), ps.requiredWords(),
+		ps.operandWords())
+}
+
+//Required options as shown in the synopsis, each with its Metavar and
+//preceded by a space, like " --output FILE".
+func (ps *Parser)requiredWords() string {
+	var b strings.Builder
+	for _, p := range ps.params {
+		o := p.base()
+		if !o.required {
+			continue
+		}
+		b.WriteString(" " + o.display())
+		if p.opt != nil {
+			b.WriteString(" " + p.opt.metavar())
+		}
+	}
+	return b.String()
+}
+
+//Operands from the operand spec as shown in the synopsis, each preceded by
+//a space, like " SRC... DST".
+func (ps *Parser)operandWords() string {
+	var b strings.Builder
+	for _, spec := range ps.operandSpec {
+		name := spec.Name
+		if spec.Variadic {
+			name += "..."
+		}
+		if spec.Optional {
+			name = "[" + name + "]"
+		}
+		b.WriteString(" " + name)
+	}
+	return b.String()
+}
+
+func (ps *Parser)writeHelp(w io.Writer) {
+	if ps.helpTemplate != nil {
+		ps.executeHelpTemplate(w)
+		return
+	}
+	if lines := ps.usageLines(); len(lines) > 0 {
+		fmt.Fprintf(w, "%s\n\n", strings.Join(lines, "\n"))
+	}
+	if ps.summary != "" {
+		fmt.Fprintf(w, "%s\n\n", ps.summary)
+	}
+	//Left column fits the longest name, up to maxHelpColumn
+	column := 0
+	for _, p := range ps.params {
+		if n := utf8.RuneCountInString(ps.helpLabel(p)); n > column && n <= maxHelpColumn {
+			column = n
+		}
+	}
+	width := ps.terminalWidth(w)
+	color := ps.colorFor(w)
+	for i, group := range ps.helpGroups() {
+		if group.title != "" {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "%s:\n", group.title)
+		}
+		for _, p := range group.params {
+			ps.showOptionHelp(w, p, column, width, color)
+		}
+	}
+	ps.writeExamples(w)
+	if ps.epilog != "" {
+		fmt.Fprintf(w, "\n%s\n", ps.epilog)
+	}
+}
@@ -0,0 +1,331 @@
+package getopts
+
+import "testing"
+import "strings"
+import "errors"
+import "os"
+
+//First word of each line of help
+func helpNames() []string {
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	names := make([]string, 0)
+	for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		names = append(names, strings.Fields(line)[0])
+	}
+	return names
+}
+
+func checkNames(t *testing.T, got, exp []string) {
+	if len(got) != len(exp) {
+		t.Fatalf("Got %v expected %v", got, exp)
+	}
+	for i := range exp {
+		if got[i] != exp[i] {
+			t.Fatalf("Got %v expected %v", got, exp)
+		}
+	}
+}
+
+//Help lists options in registration order, or alphabetically
+func TestHelpSort(t *testing.T) {
+	resetParams()
+	NewFlagLong("zeta", "Last letter")
+	NewOption('a', "alpha", "First letter")
+	NewOptionShort('m', "Middle letter")
+	checkNames(t, helpNames(), []string{ "--zeta", "-a/--alpha", "-m" })
+
+	SetHelpSort(HelpSortAlphabetical)
+	checkNames(t, helpNames(), []string{ "-a/--alpha", "-m", "--zeta" })
+}
+
+//The synopsis line shows the declared operands
+func TestOperandSpec(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	SetOperandSpec([]OperandSpec{
+		{
+			Name:		"SRC",
+			Variadic:	true,
+		},
+		{
+			Name:		"DST",
+		},
+	})
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	line := strings.Split(b.String(), "\n")[0]
+	exp := "Usage: " + CommandLine.programName() + " [options] SRC... DST"
+	if line != exp {
+		t.Fatalf("Got '%s' expected '%s'", line, exp)
+	}
+}
+
+//Help aligns the right column and wraps long help text under it
+func TestHelpWrap(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	NewOptionLong("config", "Read settings from this file instead of the default location")
+	SetHelpWidth(40)
+	var b strings.Builder
+	SetHelpOutput(&b)
+	ShowHelp()
+	exp := "-v/--verbose Increase verbosity\n" +
+		"--config     Read settings from this\n" +
+		"             file instead of the default\n" +
+		"             location\n"
+	if b.String() != exp {
+		t.Fatalf("Got\n%s\nexpected\n%s", b.String(), exp)
+	}
+}
+
+//Names too long for the left column put their help on the next line
+func TestHelpLongName(t *testing.T) {
+	resetParams()
+	NewFlag('q', "quiet", "Say less")
+	NewFlagLong("a-very-long-option-name-indeed", "Rarely used")
+	SetHelpWidth(80)
+	var b strings.Builder
+	SetHelpOutput(&b)
+	ShowHelp()
+	exp := "-q/--quiet Say less\n" +
+		"--a-very-long-option-name-indeed\n" +
+		"           Rarely used\n"
+	if b.String() != exp {
+		t.Fatalf("Got\n%s\nexpected\n%s", b.String(), exp)
+	}
+}
+
+//--help and --version print their output and return sentinel errors
+func TestAutoHelp(t *testing.T) {
+	resetParams()
+	input := NewOptionLong("input", "Input file")
+	input.SetRequired(true)
+	SetAutoHelp()
+	SetVersion("1.2.0")
+	var b strings.Builder
+	SetHelpOutput(&b)
+	_, err := ArgParse([]string{ "mytool", "--help" })
+	if !errors.Is(err, ErrHelpRequested) {
+		t.Fatalf("Got error %v, expected ErrHelpRequested", err)
+	}
+	if !strings.HasPrefix(b.String(), "--input ") {
+		t.Fatalf("Got help %s", b.String())
+	}
+
+	ResetValues()
+	b.Reset()
+	_, err = ArgParse([]string{ "mytool", "--version" })
+	if !errors.Is(err, ErrVersionRequested) || b.String() != "mytool 1.2.0\n" {
+		t.Fatalf("Got error %v and output %s", err, b.String())
+	}
+
+	ResetValues()
+	code := -1
+	ExitFunc = func(c int) {
+		code = c
+	}
+	MustParse([]string{ "mytool", "-h" })
+	if code != 0 {
+		t.Fatalf("Got exit status %d, expected 0", code)
+	}
+}
+
+//Metavars name opt-args in help, and required options appear in the
+//synopsis with them
+func TestMetavar(t *testing.T) {
+	resetParams()
+	output := NewOption('o', "output", "File to write")
+	output.Metavar = "FILE"
+	output.SetRequired(true)
+	NewOptionLong("level", "Compression level").Metavar = "N"
+	NewOptionShort('t', "Tag to add")
+	SetOperandSpec([]OperandSpec{ { Name: "SRC" } })
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	exp := "Usage: " + CommandLine.programName() + " [options] --output FILE SRC\n\n" +
+		"-o/--output FILE File to write\n" +
+		"--level N        Compression level\n" +
+		"-t               Tag to add\n"
+	if b.String() != exp {
+		t.Fatalf("Got help\n%s\nexpected\n%s", b.String(), exp)
+	}
+}
+
+//Options in help sections are listed under their titles, after the others
+func TestHelpSections(t *testing.T) {
+	resetParams()
+	host := NewOptionLong("host", "Server to connect to")
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	port := NewOptionLong("port", "Port to connect to")
+	format := NewOptionLong("format", "Output format")
+	NewFlagLong("dry-run", "Show what would be done")
+	SetHelpSection("Connection options", host, port)
+	SetHelpSection("Output options", format, verbose)
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	exp := "--dry-run    Show what would be done\n" +
+		"\n" +
+		"Connection options:\n" +
+		"--host       Server to connect to\n" +
+		"--port       Port to connect to\n" +
+		"\n" +
+		"Output options:\n" +
+		"-v/--verbose Increase verbosity\n" +
+		"--format     Output format\n"
+	if b.String() != exp {
+		t.Fatalf("Got help\n%s\nexpected\n%s", b.String(), exp)
+	}
+
+	SetHelpSectionOrder("Output options")
+	b.Reset()
+	CommandLine.writeHelp(&b)
+	if !strings.Contains(b.String(), "\nOutput options:\n") ||
+		strings.Index(b.String(), "Output") > strings.Index(b.String(), "Connection") {
+		t.Fatalf("Got help\n%s\nexpected output options first", b.String())
+	}
+}
+
+//A help template lays out help from the documented data
+func TestHelpTemplate(t *testing.T) {
+	resetParams()
+	output := NewOption('o', "output", "File to write")
+	output.Metavar = "FILE"
+	output.Default = "out.txt"
+	host := NewOptionLong("host", "Server")
+	NewFlag('v', "verbose", "Increase verbosity")
+	SetHelpSection("Connection", host)
+	SetHelpExamples([]ManExample{ { Description: "Write a.txt", Command: "tool -o a.txt" } })
+	err := SetHelpTemplate(`{{range .Sections}}{{if .Title}}== {{.Title}} ==
+{{end}}{{range .Options}}{{printf "%-*s" $.Column .Label}} | {{.RawHelp}}{{if .Default}} [{{.Default}}]{{end}}
+{{end}}{{end}}{{range .Examples}}e.g. {{.Command}}
+{{end}}`)
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	exp := "-o/--output FILE | File to write [out.txt]\n" +
+		"-v/--verbose     | Increase verbosity\n" +
+		"== Connection ==\n" +
+		"--host           | Server\n" +
+		"e.g. tool -o a.txt\n"
+	if b.String() != exp {
+		t.Fatalf("Got help\n%s\nexpected\n%s", b.String(), exp)
+	}
+	if err := SetHelpTemplate("{{.Program"); err == nil {
+		t.Fatalf("Expected an error for a malformed template")
+	}
+}
+
+//Help to something other than a terminal wraps to $COLUMNS, or 80
+func TestTerminalWidth(t *testing.T) {
+	resetParams()
+	f, err := os.CreateTemp(t.TempDir(), "help")
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	defer f.Close()
+	if _, ok := terminalSize(f); ok {
+		t.Fatalf("A regular file should not be a terminal")
+	}
+	t.Setenv("COLUMNS", "50")
+	if n := CommandLine.terminalWidth(f); n != 50 {
+		t.Fatalf("Got width %d expected 50 from $COLUMNS", n)
+	}
+	t.Setenv("COLUMNS", "")
+	if n := CommandLine.terminalWidth(f); n != defaultHelpWidth {
+		t.Fatalf("Got width %d expected %d", n, defaultHelpWidth)
+	}
+	SetHelpWidth(30)
+	if n := CommandLine.terminalWidth(f); n != 30 {
+		t.Fatalf("Got width %d expected 30 from SetHelpWidth", n)
+	}
+}
+
+//Program name, summary, usage lines, and epilog frame help and man pages
+func TestProgramMetadata(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	SetProgram("tool", "Copy files somewhere")
+	SetSynopsis("[options] SRC... DST", "[options] -t DIR SRC...")
+	SetEpilog("Report bugs to the issue tracker.")
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	exp := "Usage: tool [options] SRC... DST\n" +
+		"   or: tool [options] -t DIR SRC...\n\n" +
+		"Copy files somewhere\n\n" +
+		"-v/--verbose Increase verbosity\n" +
+		"\nReport bugs to the issue tracker.\n"
+	if b.String() != exp {
+		t.Fatalf("Got help\n%s\nexpected\n%s", b.String(), exp)
+	}
+	b.Reset()
+	if err := GenerateManPage(&b, ManMeta{}); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	for _, want := range []string{ "tool \\- Copy files somewhere\n",
+		".B tool\n[options] SRC... DST\n.br\n.B tool\n[options] \\-t DIR SRC...\n",
+		".SH NOTES\nReport bugs to the issue tracker.\n" } {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("Man page\n%s\nmissing %q", b.String(), want)
+		}
+	}
+}
+
+//Examples show after the options in help and in the man page, and
+//CheckExamples parses each
+func TestAddExample(t *testing.T) {
+	resetParams()
+	SetProgram("tool", "")
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	NewOption('o', "output", "File to write")
+	AddExample("tool -v -o 'my file.txt'", "Write my file.txt verbosely")
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	exp := "-v/--verbose Increase verbosity\n" +
+		"-o/--output  File to write\n" +
+		"\nExamples:\n" +
+		"  Write my file.txt verbosely\n" +
+		"    tool -v -o 'my file.txt'\n"
+	if b.String() != exp {
+		t.Fatalf("Got help\n%s\nexpected\n%s", b.String(), exp)
+	}
+	b.Reset()
+	if err := GenerateManPage(&b, ManMeta{}); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if !strings.Contains(b.String(), ".SH EXAMPLES\n.PP\nWrite my file.txt verbosely\n") {
+		t.Fatalf("Man page\n%s\nmissing the example", b.String())
+	}
+	if err := CheckExamples(); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if verbose.Passed {
+		t.Fatalf("Checking examples should leave values reset")
+	}
+	AddExample("tool --colour", "Misspelled")
+	if err := CheckExamples(); err == nil || !strings.Contains(err.Error(), "tool --colour") {
+		t.Fatalf("Expected an error naming the broken example, got %v", err)
+	}
+}
+
+//The default of a sensitive option is never shown
+func TestSensitiveDefault(t *testing.T) {
+	resetParams()
+	token := NewOptionLong("token", "API token")
+	token.Default = "s3cret"
+	token.SetSensitive(true)
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	if b.String() != "--token API token\n" {
+		t.Fatalf("Got help %q", b.String())
+	}
+	if data := CommandLine.helpData(&b); data.Sections[0].Options[0].Default != "" {
+		t.Fatalf("Help templates should not see the default")
+	}
+	b.Reset()
+	if err := GenerateMarkdown(&b); err != nil || strings.Contains(b.String(), "s3cret") || strings.Contains(b.String(), "***") {
+		t.Fatalf("Got error %v and docs\n%s", err, b.String())
+	}
+}
@@ -0,0 +1,143 @@
+package getopts
+
+import "fmt"
+import "io"
+import "strings"
+import "text/template"
+import "unicode/utf8"
+
+//What a help template is executed with.
+type HelpData struct {
+	//Name of the program, as in help
+	Program		string
+	//One line description given to SetProgram
+	Summary		string
+	//Usage lines, like "Usage: mytool [options] SRC", or empty if neither
+	//a synopsis nor operands are declared
+	Usage		string
+	//Options grouped by help section.  Options in no section come first,
+	//with an empty Title.
+	Sections	[]HelpSectionData
+	//Examples given to AddExample and SetHelpExamples
+	Examples	[]ManExample
+	//Text given to SetEpilog
+	Epilog		string
+	//Width of the left column in the default layout, for
+	//{{printf "%-*s" $.Column .Label}}
+	Column		int
+	//Width help is wrapped to in the default layout
+	Width		int
+}
+
+//A help section and its options.
+type HelpSectionData struct {
+	Title	string
+	Options	[]HelpOptionData
+}
+
+//A flag or option as a help template sees it.
+type HelpOptionData struct {
+	//Names as shown in the left column of the default layout, like
+	//-o/--output FILE
+	Label		string
+	//Short option with its dash, like -o, or empty
+	Short		string
+	//Long option with its dashes, like --output, or empty
+	Long		string
+	//Help text with the default and other notes added, as in the default
+	//layout
+	Help		string
+	//Help text as registered
+	RawHelp		string
+	//Placeholder for the opt-arg, or empty for flags
+	Metavar		string
+	//Default of an option, or empty if none or the option is sensitive
+	Default		string
+	TakesArg	bool
+	Required	bool
+	Deprecated	bool
+}
+
+//Render help with the text/template tmpl instead of the default layout,
+//for projects with a house style.  The template is executed with a
+//HelpData.  Returns an error if tmpl does not parse, keeping the previous
+//layout.
+func (ps *Parser)SetHelpTemplate(tmpl string) error {
+	t, err := template.New("help").Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	ps.helpTemplate = t
+	return nil
+}
+
+//Render help for CommandLine with a template.
+func SetHelpTemplate(tmpl string) error {
+	return commandLine().SetHelpTemplate(tmpl)
+}
+
+//Examples for help to show, replacing any given to AddExample.
+func (ps *Parser)SetHelpExamples(examples []ManExample) {
+	ps.helpExamples = examples
+}
+
+//Examples for help of CommandLine.
+func SetHelpExamples(examples []ManExample) {
+	commandLine().SetHelpExamples(examples)
+}
+
+//Everything help shows, for a help template writing to w.
+func (ps *Parser)helpData(w io.Writer) HelpData {
+	data := HelpData{
+		Program:	ps.programName(),
+		Summary:	ps.summary,
+		Usage:		strings.Join(ps.usageLines(), "\n"),
+		Examples:	ps.helpExamples,
+		Epilog:		ps.epilog,
+		Width:		ps.terminalWidth(w),
+	}
+	for _, group := range ps.helpGroups() {
+		section := HelpSectionData{ Title: group.title }
+		for _, p := range group.params {
+			option := ps.helpOptionData(p)
+			if n := utf8.RuneCountInString(option.Label); n > data.Column && n <= maxHelpColumn {
+				data.Column = n
+			}
+			section.Options = append(section.Options, option)
+		}
+		data.Sections = append(data.Sections, section)
+	}
+	return data
+}
+
+//What a help template sees of p.
+func (ps *Parser)helpOptionData(p parameter) HelpOptionData {
+	o := p.base()
+	option := HelpOptionData{
+		Label:		ps.helpLabel(p),
+		Help:		helpText(p),
+		RawHelp:	o.Help,
+		TakesArg:	p.takesArgument(),
+		Required:	o.required,
+		Deprecated:	o.deprecated,
+	}
+	if o.ShortOpt != 0 {
+		option.Short = "-" + string(o.ShortOpt)
+	}
+	if o.LongOpt != "" {
+		option.Long = "--" + o.LongOpt
+	}
+	if p.opt != nil {
+		option.Metavar = p.opt.metavar()
+		option.Default = p.opt.shownDefault()
+	}
+	return option
+}
+
+//Write help with the help template.  If executing it fails, the error is
+//written after whatever the template wrote.
+func (ps *Parser)executeHelpTemplate(w io.Writer) {
+	if err := ps.helpTemplate.Execute(w, ps.helpData(w)); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
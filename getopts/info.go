@@ -0,0 +1,116 @@
+package getopts
+
+import "slices"
+
+//Description of a registered flag or option for custom help, completion,
+//and documentation renderers.  It is a copy, so changing it has no effect.
+type ParamInfo struct {
+	//Short option, or 0 if none
+	Short		rune
+	//Long option, or empty if none
+	Long		string
+	Help		string
+	//Whether this is an option taking an argument rather than a flag
+	TakesArg	bool
+	//Default of an option as help shows it, so empty if it is sensitive
+	Default		string
+	//Placeholder for an option's argument, or empty for a flag
+	Metavar		string
+	//Whether Deprecate was called, and the message given to it
+	Deprecated	bool
+	Deprecation	string
+	//Title of the help section listing this, or empty if none
+	Section		string
+	//Long options besides Long given to AddLongName
+	LongNames	[]string
+}
+
+//Information about the registered flag or option p.
+func paramInfo(p parameter) ParamInfo {
+	o := p.base()
+	info := ParamInfo{
+		Short:		o.ShortOpt,
+		Long:		o.LongOpt,
+		Help:		o.Help,
+		TakesArg:	p.takesArgument(),
+		Deprecated:	o.deprecated,
+		Deprecation:	o.deprecation,
+		Section:	o.section,
+		LongNames:	slices.Clone(o.longNames),
+	}
+	if p.opt != nil {
+		info.Default = p.opt.shownDefault()
+		info.Metavar = p.opt.metavar()
+	}
+	return info
+}
+
+//Every registered flag and option, in registration order.
+func (ps *Parser)Parameters() []ParamInfo {
+	infos := make([]ParamInfo, 0, len(ps.params))
+	for _, p := range ps.params {
+		infos = append(infos, paramInfo(p))
+	}
+	return infos
+}
+
+//Every flag and option registered with CommandLine.
+func Parameters() []ParamInfo {
+	return commandLine().Parameters()
+}
+
+//The flag or option with short option s, as a *Flag or *Option, like
+//flag.Lookup.  Returns nil if there is none.
+func (ps *Parser)LookupShort(s rune) Param {
+	if p, ok := ps.paramsByShort[s]; ok {
+		return p.param()
+	}
+	return nil
+}
+
+//The flag or option of CommandLine with short option s.
+func LookupShort(s rune) Param {
+	return commandLine().LookupShort(s)
+}
+
+//The flag or option with long option l, which must be given in full, as a
+//*Flag or *Option.  Returns nil if there is none.
+func (ps *Parser)LookupLong(l string) Param {
+	if p, ok := ps.paramsByLong[l]; ok {
+		return p.param()
+	}
+	return nil
+}
+
+//The flag or option of CommandLine with long option l.
+func LookupLong(l string) Param {
+	return commandLine().LookupLong(l)
+}
+
+//Call fn with every registered flag and option, as a *Flag or *Option, in
+//registration order, like flag.VisitAll.
+func (ps *Parser)VisitAll(fn func(Param)) {
+	for _, p := range ps.params {
+		fn(p.param())
+	}
+}
+
+//Call fn with every flag and option of CommandLine.
+func VisitAll(fn func(Param)) {
+	commandLine().VisitAll(fn)
+}
+
+//Call fn with every flag and option given a value by the last parse, or
+//negated, in registration order, like flag.Visit.
+func (ps *Parser)Visit(fn func(Param)) {
+	for _, p := range ps.params {
+		if p.passed() {
+			fn(p.param())
+		}
+	}
+}
+
+//Call fn with every flag and option of CommandLine set by the last parse.
+func Visit(fn func(Param)) {
+	commandLine().Visit(fn)
+}
@@ -0,0 +1,82 @@
+package getopts
+
+import "reflect"
+import "testing"
+
+//Parameter information matches what was registered
+func TestParameters(t *testing.T) {
+	resetParams()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	verbose.Deprecate("use --log-level")
+	output := NewOptionLong("output", "Output file")
+	output.Default = "out.txt"
+	output.Metavar = "FILE"
+	AddLongName(output, "out-file")
+	SetHelpSection("Files", output)
+	token := NewOptionLong("token", "API token")
+	token.Default = "secret"
+	token.SetSensitive(true)
+	exp := []ParamInfo{
+		{
+			Short:		'v',
+			Long:		"verbose",
+			Help:		"Increase verbosity",
+			TakesArg:	false,
+			Deprecated:	true,
+			Deprecation:	"use --log-level",
+		},
+		{
+			Long:		"output",
+			Help:		"Output file",
+			TakesArg:	true,
+			Default:	"out.txt",
+			Metavar:	"FILE",
+			Section:	"Files",
+			LongNames:	[]string{ "out-file" },
+		},
+		{
+			Long:		"token",
+			Help:		"API token",
+			TakesArg:	true,
+			Metavar:	"ARG",
+		},
+	}
+	infos := Parameters()
+	if len(infos) != len(exp) {
+		t.Fatalf("Got %v expected %v", infos, exp)
+	}
+	for i := range exp {
+		if !reflect.DeepEqual(infos[i], exp[i]) {
+			t.Fatalf("Got %v expected %v", infos[i], exp[i])
+		}
+	}
+}
+
+//Flags and options can be looked up by name and visited
+func TestLookupVisit(t *testing.T) {
+	resetParams()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	output := NewOptionLong("output", "Output file")
+	jobs := NewIntOption('j', "jobs", "Number of jobs")
+	if LookupShort('v') != verbose || LookupLong("output") != output || LookupShort('j') != jobs.Option {
+		t.Fatalf("Lookup did not find the registered flags and options")
+	}
+	if LookupShort('x') != nil || LookupLong("out") != nil {
+		t.Fatalf("Lookup found a flag or option that is not registered")
+	}
+	_, err := ArgParse([]string{ "test", "--output=a.txt", "+v" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	all := make([]Param, 0)
+	VisitAll(func(p Param) {
+		all = append(all, p)
+	})
+	passed := make([]Param, 0)
+	Visit(func(p Param) {
+		passed = append(passed, p)
+	})
+	if len(all) != 3 || len(passed) != 2 || passed[0] != verbose || passed[1] != output {
+		t.Fatalf("Got %v visiting all and %v visiting passed", all, passed)
+	}
+}
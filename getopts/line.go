@@ -0,0 +1,86 @@
+package getopts
+
+import "fmt"
+import "strings"
+
+const(
+	errLineQuote = "Unbalanced %c quote in line:  %s"
+	errLineEscape = "Line ends in an escape:  %s"
+)
+
+//Split line into words the way a shell would, without expanding anything.
+//White space separates words.  Inside single quotes every character is
+//literal.  Inside double quotes a backslash escapes only " and \.
+//Elsewhere a backslash escapes any character, and a backslash before a
+//newline joins the lines.  Quotes can make an empty word, as in ''.
+func tokenizeLine(line string) ([]string, error) {
+	words := make([]string, 0)
+	var word strings.Builder
+	//Whether a word has started, even if it is empty so far
+	inWord := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\\':
+			if i + 1 >= len(line) {
+				return nil, fmt.Errorf(errLineEscape, line)
+			}
+			i++
+			if line[i] != '\n' {
+				word.WriteByte(line[i])
+				inWord = true
+			}
+		case c == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf(errLineQuote, c, line)
+			}
+			word.WriteString(line[i+1:i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i + 1 < len(line) && (line[i+1] == '"' || line[i+1] == '\\') {
+					i++
+				}
+				word.WriteByte(line[i])
+			}
+			if i >= len(line) {
+				return nil, fmt.Errorf(errLineQuote, c, line)
+			}
+			inWord = true
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+//Parse a command given as a single line, split into words honoring quotes
+//and backslash escapes like a shell, for REPLs and commands read from a
+//socket.  So commit -m "fix the \"bug\"" passes fix the "bug" to -m.
+//Unlike Parse, there is no program name.  Nothing is expanded, so $HOME
+//and *.txt stay as they are.
+func (ps *Parser)ParseLine(line string) ([]Rest, error) {
+	words, err := tokenizeLine(line)
+	if err != nil {
+		return nil, err
+	}
+	return ps.parseWords(words)
+}
+
+//Parse a command given as a single line with CommandLine, honoring quotes.
+func ParseLine(line string) ([]Rest, error) {
+	return commandLine().ParseLine(line)
+}
@@ -0,0 +1,58 @@
+package getopts
+
+import "testing"
+
+//Lines are split like a shell would split them
+func TestTokenizeLine(t *testing.T) {
+	cases := []struct {
+		line	string
+		exp	[]string
+	}{
+		{ "a  b\tc", []string{ "a", "b", "c" } },
+		{ `say 'it''s' "a \"b\" \c"`, []string{ "say", "its", `a "b" \c` } },
+		{ `a\ b c\\d`, []string{ "a b", `c\d` } },
+		{ `'' x""y`, []string{ "", "xy" } },
+		{ "a \\\nb", []string{ "a", "b" } },
+		{ "", []string{} },
+	}
+	for _, c := range cases {
+		words, err := tokenizeLine(c.line)
+		if err != nil {
+			t.Fatalf("%s: error %s", c.line, err)
+		}
+		if len(words) != len(c.exp) {
+			t.Fatalf("%s: got %q expected %q", c.line, words, c.exp)
+		}
+		for i := range c.exp {
+			if words[i] != c.exp[i] {
+				t.Fatalf("%s: got %q expected %q", c.line, words, c.exp)
+			}
+		}
+	}
+	for _, line := range []string{ `a 'b`, `a "b`, `a \` } {
+		if _, err := tokenizeLine(line); err == nil {
+			t.Fatalf("%s: expected an error", line)
+		}
+	}
+}
+
+//ParseLine parses quoted words as single arguments
+func TestParseLine(t *testing.T) {
+	resetParams()
+	message := NewOption('m', "message", "Commit message")
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	rest, err := ParseLine(`-v -m "fix the \"bug\"" 'my file.txt'`)
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if !verbose.Passed || message.OptArg != `fix the "bug"` {
+		t.Fatalf("Got verbose %v and message %s", verbose.Passed, message.OptArg)
+	}
+	if len(rest) != 1 || rest[0].Argument != "my file.txt" || rest[0].Index != 4 {
+		t.Fatalf("Got %v, expected my file.txt", rest)
+	}
+	_, err = ParseLine(`-m "unfinished`)
+	if err == nil || err.Error() != `Unbalanced " quote in line:  -m "unfinished` {
+		t.Fatalf("Got error %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package getopts
+
+import "fmt"
+import "io"
+import "strings"
+
+//Information for a man page beyond what the registered options provide.
+type ManMeta struct {
+	//Name of the program, or empty for the name used in help
+	Name		string
+	//One line description for the NAME section, or empty for the one
+	//given to SetProgram
+	Summary		string
+	//Paragraphs for the DESCRIPTION section, which is left out if empty
+	Description	[]string
+	//Date shown in the footer, like "January 2025"
+	Date		string
+	//Source shown in the footer, usually the package and version
+	Source		string
+	//Title of the manual shown in the header, like "User Commands"
+	Manual		string
+	//Examples for the EXAMPLES section, before those given to AddExample.
+	//The section is left out if there are none.
+	Examples	[]ManExample
+}
+
+//A command shown in the EXAMPLES section of a man page.
+type ManExample struct {
+	//What the example does
+	Description	string
+	//The command itself
+	Command		string
+}
+
+//Escape s for roff:  backslashes and hyphens are escaped, and a line may
+//not start with a period or apostrophe, which roff reads as a request.
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\e")
+	s = strings.ReplaceAll(s, "-", "\\-")
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = "\\&" + s
+	}
+	return s
+}
+
+//Names of p in bold as they appear in the OPTIONS section, with its
+//Metavar, or ARG, in italics for options taking an argument.
+func manLabel(p parameter) string {
+	o := p.base()
+	names := make([]string, 0, 2)
+	if o.ShortOpt != 0 {
+		names = append(names, "\\fB" + roffEscape("-" + string(o.ShortOpt)) + "\\fR")
+	}
+	if o.LongOpt != "" {
+		names = append(names, "\\fB" + roffEscape("--" + o.LongOpt) + "\\fR")
+	}
+	label := strings.Join(names, ", ")
+	if p.takesArgument() {
+		label += " \\fI" + roffEscape(p.opt.metavar()) + "\\fR"
+	}
+	return label
+}
+
+//Write a section 1 man page in roff, with NAME, SYNOPSIS, DESCRIPTION,
+//OPTIONS from the registered flags and options, NOTES from SetEpilog, and
+//EXAMPLES sections.  View it with man -l.
+func (ps *Parser)GenerateManPage(w io.Writer, meta ManMeta) error {
+	name := meta.Name
+	if name == "" {
+		name = ps.programName()
+	}
+	summary := meta.Summary
+	if summary == "" {
+		summary = ps.summary
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH %s 1 \"%s\" \"%s\" \"%s\"\n", roffEscape(strings.ToUpper(name)),
+		meta.Date, meta.Source, meta.Manual)
+	b.WriteString(".SH NAME\n")
+	if summary != "" {
+		fmt.Fprintf(&b, "%s \\- %s\n", roffEscape(name), roffEscape(summary))
+	} else {
+		fmt.Fprintf(&b, "%s\n", roffEscape(name))
+	}
+	b.WriteString(".SH SYNOPSIS\n")
+	if len(ps.synopsisLines) == 0 {
+		fmt.Fprintf(&b, ".B %s\n", roffEscape(name))
+		fmt.Fprintf(&b, "[\\fIoptions\\fR]%s\n", roffEscape(ps.requiredWords() + ps.operandWords()))
+	}
+	for i, line := range ps.synopsisLines {
+		if i > 0 {
+			b.WriteString(".br\n")
+		}
+		fmt.Fprintf(&b, ".B %s\n%s\n", roffEscape(name), roffEscape(line))
+	}
+	if len(meta.Description) > 0 {
+		b.WriteString(".SH DESCRIPTION\n")
+		for i, paragraph := range meta.Description {
+			if i > 0 {
+				b.WriteString(".PP\n")
+			}
+			fmt.Fprintf(&b, "%s\n", roffEscape(paragraph))
+		}
+	}
+	if len(ps.params) > 0 {
+		b.WriteString(".SH OPTIONS\n")
+		for _, group := range ps.helpGroups() {
+			if group.title != "" {
+				fmt.Fprintf(&b, ".SS %s\n", roffEscape(group.title))
+			}
+			for _, p := range group.params {
+				fmt.Fprintf(&b, ".TP\n%s\n%s\n", manLabel(p), roffEscape(helpText(p)))
+			}
+		}
+	}
+	if ps.epilog != "" {
+		fmt.Fprintf(&b, ".SH NOTES\n%s\n", roffEscape(ps.epilog))
+	}
+	examples := append(append([]ManExample{}, meta.Examples...), ps.helpExamples...)
+	if len(examples) > 0 {
+		b.WriteString(".SH EXAMPLES\n")
+		for _, example := range examples {
+			fmt.Fprintf(&b, ".PP\n%s\n", roffEscape(example.Description))
+			fmt.Fprintf(&b, ".PP\n.RS\n\\fB%s\\fR\n.RE\n", roffEscape(example.Command))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+//Write a man page for CommandLine.
+func GenerateManPage(w io.Writer, meta ManMeta) error {
+	return commandLine().GenerateManPage(w, meta)
+}
@@ -0,0 +1,62 @@
+package getopts
+
+import "testing"
+import "strings"
+
+//The man page has a section for each part of the metadata and every option
+func TestGenerateManPage(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	output := NewOptionLong("output", "Output file")
+	output.Default = "out.txt"
+	SetOperandSpec([]OperandSpec{
+		{
+			Name:		"FILE",
+			Variadic:	true,
+		},
+	})
+	var b strings.Builder
+	err := GenerateManPage(&b, ManMeta{
+		Name:		"mytool",
+		Summary:	"process files",
+		Description:	[]string{ "Processes each FILE." },
+		Date:		"January 2025",
+		Source:		"mytool 1.0",
+		Manual:		"User Commands",
+		Examples:	[]ManExample{
+			{
+				Description:	"Process two files verbosely:",
+				Command:	"mytool -v a.txt b.txt",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	exp := `.TH MYTOOL 1 "January 2025" "mytool 1.0" "User Commands"
+.SH NAME
+mytool \- process files
+.SH SYNOPSIS
+.B mytool
+[\fIoptions\fR] FILE...
+.SH DESCRIPTION
+Processes each FILE.
+.SH OPTIONS
+.TP
+\fB\-v\fR, \fB\-\-verbose\fR
+Increase verbosity
+.TP
+\fB\-\-output\fR \fIARG\fR
+Output file (default: out.txt)
+.SH EXAMPLES
+.PP
+Process two files verbosely:
+.PP
+.RS
+\fBmytool \-v a.txt b.txt\fR
+.RE
+`
+	if b.String() != exp {
+		t.Fatalf("Got\n%s\nexpected\n%s", b.String(), exp)
+	}
+}
@@ -0,0 +1,97 @@
+package getopts
+
+import "fmt"
+import "io"
+import "strings"
+
+//Escape s for a Markdown paragraph or table cell:  characters Markdown
+//reads as formatting are backslash escaped, and newlines become spaces so
+//a cell stays on its row.
+func markdownEscape(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '\\', '|', '*', '_', '`', '<', '[', ']':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case '\n':
+			b.WriteByte(' ')
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+//s as inline code, or empty if s is.  Pipes are still escaped, since
+//tables split rows on them before reading code spans.
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(s, "|", "\\|") + "`"
+}
+
+//Usage lines for a docs page, each starting with the program name.
+func (ps *Parser)markdownUsage() []string {
+	if len(ps.synopsisLines) == 0 {
+		return []string{ ps.programName() + " [options]" + ps.requiredWords() + ps.operandWords() }
+	}
+	lines := make([]string, len(ps.synopsisLines))
+	for i, line := range ps.synopsisLines {
+		lines[i] = ps.programName() + " " + line
+	}
+	return lines
+}
+
+//Write a Markdown docs page, with the program name as its title, the
+//summary given to SetProgram, usage, a table of the registered flags and
+//options for each help section giving their defaults and environment
+//variables, the examples given to SetHelpExamples, and the epilog.
+func (ps *Parser)GenerateMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", markdownEscape(ps.programName()))
+	if ps.summary != "" {
+		fmt.Fprintf(&b, "%s\n\n", markdownEscape(ps.summary))
+	}
+	fmt.Fprintf(&b, "## Usage\n\n```\n%s\n```\n", strings.Join(ps.markdownUsage(), "\n"))
+	if len(ps.params) > 0 {
+		b.WriteString("\n## Options\n")
+	}
+	for _, group := range ps.helpGroups() {
+		if group.title != "" {
+			fmt.Fprintf(&b, "\n### %s\n", markdownEscape(group.title))
+		}
+		b.WriteString("\n| Option | Description | Default | Environment |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for _, p := range group.params {
+			o := p.base()
+			var def string
+			if p.opt != nil {
+				def = markdownCode(p.opt.shownDefault())
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", markdownCode(ps.helpLabel(p)),
+				markdownEscape(o.Help + helpNotes(p, false)), def,
+				markdownCode(ps.envVar(o)))
+		}
+	}
+	if len(ps.helpExamples) > 0 {
+		b.WriteString("\n## Examples\n")
+		for _, example := range ps.helpExamples {
+			if example.Description != "" {
+				fmt.Fprintf(&b, "\n%s\n", markdownEscape(example.Description))
+			}
+			fmt.Fprintf(&b, "\n```\n%s\n```\n", example.Command)
+		}
+	}
+	if ps.epilog != "" {
+		fmt.Fprintf(&b, "\n%s\n", markdownEscape(ps.epilog))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+//Write a Markdown docs page for CommandLine.
+func GenerateMarkdown(w io.Writer) error {
+	return commandLine().GenerateMarkdown(w)
+}
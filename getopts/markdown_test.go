@@ -0,0 +1,41 @@
+package getopts
+
+import "testing"
+import "strings"
+
+//The docs page has a table row for every option, with its default and
+//environment variable
+func TestGenerateMarkdown(t *testing.T) {
+	resetParams()
+	SetProgram("mytool", "Process files")
+	SetEnvPrefix("MYTOOL_")
+	NewFlag('v', "verbose", "Increase verbosity")
+	output := NewOptionLong("output", "Output file")
+	output.Default = "out.txt"
+	output.Metavar = "FILE"
+	mode := NewChoiceOption(0, "mode", "How to *process*", []string{ "fast", "slow" })
+	SetHelpSection("Tuning", mode)
+	SetHelpExamples([]ManExample{ { Description: "Process a file:", Command: "mytool a.txt" } })
+	SetEpilog("See the project site.")
+	var b strings.Builder
+	if err := GenerateMarkdown(&b); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	exp := "# mytool\n\n" +
+		"Process files\n\n" +
+		"## Usage\n\n```\nmytool [options]\n```\n\n" +
+		"## Options\n\n" +
+		"| Option | Description | Default | Environment |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| `-v/--verbose` | Increase verbosity |  | `MYTOOL_VERBOSE` |\n" +
+		"| `--output FILE` | Output file | `out.txt` | `MYTOOL_OUTPUT` |\n" +
+		"\n### Tuning\n\n" +
+		"| Option | Description | Default | Environment |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| `--mode {fast\\|slow}` | How to \\*process\\* |  | `MYTOOL_MODE` |\n" +
+		"\n## Examples\n\nProcess a file:\n\n```\nmytool a.txt\n```\n" +
+		"\nSee the project site.\n"
+	if b.String() != exp {
+		t.Fatalf("Got\n%s\nexpected\n%s", b.String(), exp)
+	}
+}
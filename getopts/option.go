@@ -14,11 +14,17 @@
 //it comes after '--'.  This is to allow special handling of arguments like '-',
 //which is usually used to read standard-input, but can also be the name of a file.
 //Analogously, can be used for other arguments that may be commands, or file names.
+//
+//Flags and options are registered with a Parser, which parses them.  The
+//package level functions use CommandLine, the Parser for the program's
+//own command line.
 package getopts
 
-import "os"
 import "fmt"
 import "strings"
+import "strconv"
+import "regexp"
+import "context"
 
 //This struct contains the argument passed
 //and whether it was before or after '--'
@@ -27,6 +33,9 @@ type Rest struct {
 	Argument	string
 	//Whether this argument comes after '--'
 	AfterDashes	bool
+	//Position in argv, where argv[0] is the program name.  ParseFunc,
+	//ParseString, and ParseLine count from 1 in the same way.
+	Index	int
 }
 
 //Command line options that take arguments.  Each subsequent occurence of the option
@@ -44,6 +53,55 @@ type Option struct {
 	//If present, this function is called with the opt-arg as an argument as soon as it
 	//is parsed.
 	Action	func(string)
+	//Like Action, but called after it with the context given to
+	//ParseContext.  An error stops parsing and is returned from it.
+	ActionContext	func(context.Context, string) error
+	//If present, called with each opt-arg before it is stored.  An error
+	//rejects the opt-arg, and parsing fails with an ErrValidation naming
+	//the option and where the opt-arg was on the command line.
+	Validate	func(string) error
+	//If not empty, OptArg when the option is not passed, shown in help
+	Default	string
+	//If not empty, placeholder for the opt-arg in help and man pages, like
+	//FILE in --output FILE
+	Metavar	string
+	//If not empty, each opt-arg is split on this separator and every field
+	//is appended to OptArgs.
+	splitOn	string
+	//Whether double quotes protect the separator when splitting.
+	splitQuoteAware	bool
+	//Whether a backslash protects the character after it when splitting.
+	splitEscapes	bool
+	//If not nil, every opt-arg must match
+	pattern	*regexp.Regexp
+	//Whether the opt-arg extends to the end of the line in ParseString
+	restOfLine	bool
+	//If not empty, a value from the environment is a list split on this
+	envListSep	string
+	//Number of arguments taken each time the option is passed, if more
+	//than one
+	nargs	int
+	//Whether every argument after the option's first is added to OptArgs
+	//without being parsed
+	consumesRemainder	bool
+	//Whether the value is hidden from introspection and messages
+	sensitive	bool
+	//If not nil, converts each opt-arg for a typed option, rejecting
+	//those it cannot convert
+	convert	func(string) error
+	//If not nil, the converted value of a typed option, for Get
+	typed	func() any
+	//If not nil, sets the converted value of a typed option back to its
+	//zero value, for Clear
+	zero	func()
+	//If not empty, the only opt-args accepted
+	choices	[]string
+	//Whether the opt-arg must be attached, as in --color=never or -cnever,
+	//and is implicitArg if it is not
+	optionalArg	bool
+	implicitArg	string
+	//If not empty, describes the forms of opt-arg accepted, for help
+	syntax	string
 }
 
 //Command line flag.  Stores the most recent value as boolean, and saves net count, so
@@ -59,405 +117,373 @@ type Flag struct {
 	//If present, function called each time flag is negated
 	//by +f or --flag=false
 	OnFalse	func()
+	//Like OnTrue and OnFalse, but called after them with the context
+	//given to ParseContext.  An error stops parsing and is returned from it.
+	OnTrueContext	func(context.Context) error
+	OnFalseContext	func(context.Context) error
+	//Whether passing this flag skips the checks after parsing
+	bypassRequired	bool
 }
 
 //Common information for options.
 type option struct {
-	ShortOpt	byte
+	ShortOpt	rune
 	LongOpt		string
 	Help		string
 	Passed		bool
 	takesArg	bool
+	//Whether this option must always be passed
+	required	bool
+	//If not empty, environment variable read when this option is not passed
+	env	string
+	//If not nil, this option must be passed when that flag is
+	requiredIf	*Flag
+	//Options that must be passed when this one is
+	requires	[]*option
+	//Whether using this option gives a warning, and what it says to do
+	//instead
+	deprecated	bool
+	deprecation	string
+	//Long options besides LongOpt that name this option
+	longNames	[]string
+	//Title of the help section this option is listed in, or empty
+	section	string
+	//Where the current value came from
+	source	Source
 }
 
-//Assign value to flag, update count, and invoke event if applicable.
-func (f *Flag)takeValue(value bool) {
-	if value {
-		f.Count++
-	} else {
-		f.Count--
-	}
-	f.Passed = value
-	if value && f.OnTrue != nil {
-		f.OnTrue()
-	} else if !value && f.OnFalse != nil {
-		f.OnFalse()
-	}
+//Forget the flag's value and count, as if it had never been parsed.
+func (f *Flag)Clear() {
+	f.Count = 0
+	f.Passed = false
+	f.source = Source{}
+}
+
+//Option or flag.  Exists mostly so they can be stored in same
+//array without using 'any'.  Exactly one of opt and flag is set, so the
+//parser can tell which it has without an interface call or type assertion.
+type parameter struct {
+	opt	*Option
+	flag	*Flag
 }
 
 //Whether this is an option that takes an argument -> true
 //or a flag -> false
-func (o option)takesArgument() bool {
-	return o.takesArg
+func (p parameter)takesArgument() bool {
+	return p.opt != nil
 }
 
-//Option or flag.  Exists mostly so they can be stored in same
-//array with using 'any'.
-type parameter interface {
-	takesArgument() bool
-}
-
-//Add argument to Rest array.  If OnRestArg is not nil, we invoke it
-//on the argument, and we add it to array only if that function returns
-//true.  This is to support cases where the program interprets some sort
-//of command language or similar.
-func addRest(rest []Rest, arg string, dash bool) []Rest {
-	if OnRestArg != nil {
-		if OnRestArg(arg, dash) {
-			rest = append(rest, Rest{
-				Argument:	arg,
-				AfterDashes:		dash,
-			})
-		}
-	} else {
-		rest = append(rest, Rest{
-			Argument:	arg,
-			AfterDashes:		dash,
-		})
+//The *Flag or *Option p holds.
+func (p parameter)param() Param {
+	if p.opt != nil {
+		return p.opt
 	}
-	return rest
+	return p.flag
 }
 
-//Add option argument to optarg vector and invoke
-//event if applicable.
-func (o *Option)addOptArg(arg string) {
-	o.OptArgs = append(o.OptArgs, arg)
-	o.OptArg = arg
-	o.Passed = true
-	if o.Action != nil {
-		o.Action(arg)
+//Whether p was given a value by the last parse, or for a flag, negated.
+func (p parameter)passed() bool {
+	if p.opt != nil {
+		return p.opt.Passed
 	}
+	return p.flag.Passed || p.flag.Count != 0
 }
 
+//Access the information common to options and flags.
+func (p parameter)base() *option {
+	if p.opt != nil {
+		return &p.opt.option
+	}
+	return &p.flag.option
+}
 
-var Options []Option = make([]Option, 0)
+//A flag or option, for functions that accept either, like
+//NewExclusiveGroup.  *Flag, *Option, and the typed options implement it.
+type Param interface {
+	common() *option
+}
 
-var Flags []Flag = make([]Flag, 0)
+//Information common to options and flags, to implement Param.
+func (o *option)common() *option {
+	return o
+}
 
-var paramsByShort map[byte]parameter = make(map[byte]parameter)
+//Name used to refer to the option: the long option if present,
+//otherwise the short option.
+func (o *option)name() string {
+	if o.LongOpt != "" {
+		return o.LongOpt
+	}
+	return string(o.ShortOpt)
+}
 
-var paramsByLong map[string]parameter = make(map[string]parameter)
+//Name with dashes as it would be written on the command line, for
+//error messages.
+func (o *option)display() string {
+	if o.LongOpt != "" {
+		return "--" + o.LongOpt
+	}
+	return "-" + string(o.ShortOpt)
+}
 
-var OnRestArg func(arg string, afterDash bool) bool
+//Keep the option working but warn whenever it is used, with msg saying what
+//to do instead, like "use --color".  The warning goes to OnWarning, or
+//standard error, once per parse.  Help marks the option as deprecated.
+func (o *option)Deprecate(msg string) {
+	o.deprecated = true
+	o.deprecation = msg
+}
 
-func resetParams() {
-	paramsByShort = make(map[byte]parameter)
-	paramsByLong = make(map[string]parameter)
-	Options = make([]Option, 0)
-	Flags = make([]Flag, 0)
-	OnRestArg = nil
+//Split opt-args on sep before adding them to OptArgs, so that
+//--include=a,b,c is the same as --include=a --include=b --include=c.
+//OptArg still holds the value as it was passed.
+func (o *Option)SetSplitOn(sep string) {
+	o.splitOn = sep
 }
 
-func parseFlagOpt(flag, value string) (bool, error) {
-	if strings.EqualFold(value, "f") {
-		return false, nil
-	} else if strings.EqualFold(value, "t") {
-		return true, nil
-	}
+//When splitting on a separator, treat separators inside double quotes
+//as part of the field, so '"a,b",c' splits into a,b and c.
+func (o *Option)SetSplitQuoteAware(aware bool) {
+	o.splitQuoteAware = aware
+}
 
-	if strings.EqualFold(value, "y") {
-		return true, nil
-	} else if strings.EqualFold(value, "n") {
-		return false, nil
-	}
+//When splitting on a separator, make a backslash take the character after
+//it literally, so 'a\,b,c' splits into a,b and c.  Help says so.
+func (o *Option)SetSplitEscapes(escapes bool) {
+	o.splitEscapes = escapes
+}
 
-	if strings.EqualFold(value, "no") {
-		return false, nil
-	} else if strings.EqualFold(value, "yes") {
-		return true, nil
+//Split arg on sep.  If quoteAware, double quotes group characters into
+//the current field and are removed.  If escapes, a backslash makes the
+//character after it literal; a trailing backslash is kept.
+func splitValue(arg, sep string, quoteAware, escapes bool) ([]string, bool) {
+	if !quoteAware && !escapes {
+		return strings.Split(arg, sep), true
 	}
 
-	if strings.EqualFold(value, "true") {
-		return true, nil
-	} else if strings.EqualFold(value, "false") {
-		return false, nil
+	fields := make([]string, 0)
+	var field strings.Builder
+	inQuotes := false
+	for i := 0; i < len(arg); {
+		if escapes && arg[i] == '\\' && i + 1 < len(arg) {
+			field.WriteByte(arg[i+1])
+			i += 2
+		} else if quoteAware && arg[i] == '"' {
+			inQuotes = !inQuotes
+			i++
+		} else if !inQuotes && strings.HasPrefix(arg[i:], sep) {
+			fields = append(fields, field.String())
+			field.Reset()
+			i += len(sep)
+		} else {
+			field.WriteByte(arg[i])
+			i++
+		}
 	}
-
-	return false, fmt.Errorf(errPassedOptargToFlag, flag)
+	fields = append(fields, field.String())
+	return fields, !inQuotes
 }
 
-//Ensure duplicate flags/options cannot be created
-func checkShort(s byte) {
-	if _, ok := paramsByShort[s]; ok {
-		panic("Adding another command line option with same short option")
+//Fields of opt-arg arg, split as SetSplitOn and the settings after it say.
+func (o *Option)split(arg string) ([]string, error) {
+	fields, ok := splitValue(arg, o.splitOn, o.splitQuoteAware, o.splitEscapes)
+	if !ok {
+		return nil, fmt.Errorf(errUnbalancedQuotes, o.name())
 	}
+	return fields, nil
 }
 
-func checkLong(l string) {
-	if _, ok := paramsByLong[l]; ok {
-		panic("Adding another command line option with same long option")
-	}
+//Require every opt-arg to match re.
+func (o *Option)SetPattern(re *regexp.Regexp) {
+	o.pattern = re
 }
 
-func NewFlag(s byte, l string, h string) *Flag {
-	checkShort(s)
-	checkLong(l)
-
-	flag := Flag{
-		option:	option{
-			ShortOpt:	s,
-			LongOpt:	l,
-			Help:		h,
-			takesArg:	false,
-		},
+//Require every opt-arg to match the regular expression expr.  Returns an
+//error if expr does not compile.
+func (o *Option)SetPatternString(expr string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return err
 	}
-
-	Flags = append(Flags, flag)
-	paramsByShort[s] = &flag
-	paramsByLong[l] = &flag
-	return &flag
+	o.pattern = re
+	return nil
 }
 
-func NewFlagShort(s byte, h string) *Flag {
-	checkShort(s)
-	flag := Flag{
-		option:	option{
-			ShortOpt:	s,
-			Help:		h,
-			takesArg:	false,
-		},
-	}
+//In ParseString, make the opt-arg everything from the option to the end
+//of the line, so commit -m this is my message gives "this is my message"
+//without quotes.  Parsing stops there, so '--' and option-like words
+//become part of the opt-arg.  Has no effect on ArgParse.
+func (o *Option)SetRestOfLine(rest bool) {
+	o.restOfLine = rest
+}
 
-	Flags = append(Flags, flag)
-	paramsByShort[s] = &flag
-	return &flag
+//Mark the option's value as secret, like a password or token.  OptArg is
+//unaffected, but DumpState, Snapshot, ExportEnv, and error messages show
+//the value as ***.
+func (o *Option)SetSensitive(sensitive bool) {
+	o.sensitive = sensitive
 }
 
-func NewFlagLong(l string, h string) *Flag {
-	checkLong(l)
-	flag := Flag{
-		option:	option{
-			LongOpt:	l,
-			Help:		h,
-			takesArg:	false,
-		},
+//Default as help and documentation show it:  never for a sensitive option,
+//whose default may itself be a secret.
+func (o *Option)shownDefault() string {
+	if o.sensitive {
+		return ""
 	}
+	return o.Default
+}
 
-	Flags = append(Flags, flag)
-	paramsByLong[l] = &flag
-	return &flag
-}
-
-func NewOption(s byte, l string, h string) *Option {
-	checkShort(s)
-	checkLong(l)
-	opt := Option{
-		option: option{
-			LongOpt:	l,
-			ShortOpt:	s,
-			Help:		h,
-			takesArg:	true,
-		},
+//Value v, or *** if the option is sensitive.
+func (o *Option)masked(v string) string {
+	if o.sensitive {
+		return "***"
 	}
-
-	Options = append(Options, opt)
-	paramsByShort[s] = &opt
-	paramsByLong[l] = &opt
-	return &opt
+	return v
 }
 
-func NewOptionShort(s byte, h string) *Option {
-	checkShort(s)
-	opt := Option{
-		option: option{
-			ShortOpt:	s,
-			Help:		h,
-			takesArg:	true,
-		},
+//Text of err, from a validator or a Value, with v replaced by *** if the
+//option is sensitive, since such errors often repeat the value rejected.
+func (o *Option)maskedError(err error, v string) string {
+	if !o.sensitive || v == "" {
+		return err.Error()
 	}
-
-	Options = append(Options, opt)
-	paramsByShort[s] = &opt
-	return &opt
+	return strings.ReplaceAll(err.Error(), v, "***")
 }
 
-func NewOptionLong(l string, h string) *Option {
-	checkLong(l)
-	opt := Option{
-		option: option{
-			LongOpt:	l,
-			Help:		h,
-			takesArg:	true,
-		},
-	}
+//Once the option is passed, append every remaining argument to OptArgs
+//unparsed, as with --exec ls -la /tmp, so options meant for another command
+//need no '--' before them.  OptArg is the first of them.
+func (o *Option)SetConsumesRemainder(consumes bool) {
+	o.consumesRemainder = consumes
+}
 
-	Options = append(Options, opt)
-	paramsByLong[l] = &opt
-	return &opt
+//Make the option take exactly n arguments each time it is passed, so with
+//n of 2, --point X Y appends X and Y to OptArgs.  An argument attached with
+//--point=X or -pX is the first of them.  It is an error for fewer than n
+//arguments to remain.  OptArg is the last of the n.
+func (o *Option)SetArity(n int) {
+	o.nargs = n
 }
 
-const(
-	errUnrecognizedShort = "Unrecognized short option:  %c"
-	errUnrecognizedLong = "Unrecognized long option:  %s"
-	errTriedToNegateOptArg = "Passed negation for option expecting argument: %c"
-	errPassedOptargToFlag = "Passed non-boolean option to flag:  %s"
-)
+//Make the argument optional, like '::' in getopt.  --color or -c alone
+//takes bare as its opt-arg, while --color=never or -cnever takes never.  An
+//argument must be attached to be used, so in --color never, never is an
+//operand.
+func (o *Option)SetOptionalArg(bare string) {
+	o.optionalArg = true
+	o.implicitArg = bare
+}
 
-func ArgParse(argv []string) ([]Rest, error) {
-	i := 1
-	argc := len(argv)
-	rest := make([]Rest, 0)
-	expect_optarg := false
-	var waiting_opt *Option
-	for ; i < argc; i++ {
-		arg := argv[i]
-		if expect_optarg {
-			waiting_opt.addOptArg(arg)
-			expect_optarg = false
-			continue
-		}
+//Placeholder for the opt-arg:  Metavar, or ARG if it is empty.
+func (o *Option)metavar() string {
+	if o.Metavar != "" {
+		return o.Metavar
+	}
+	return "ARG"
+}
 
-		l := len(arg)
-		switch l {
-		case 0:		//Ignore empty arguments
-		case 1: 	//Either '-' or an argument
-			//rest = append(rest, arg)
-			rest = addRest(rest, arg, false)
-		case 2: 	//Either -a, +b, --, or rest
-			if arg == "--" {
-				for i++; i < argc; i++ {
-					//rest = append(rest, arg)
-					rest = addRest(rest, argv[i], true)
-				}
-				return rest, nil
-			} else if arg[0] == '-' {
-				if p, ok := paramsByShort[arg[1]]; ok {
-					if p.takesArgument() {
-						waiting_opt = p.(*Option)
-						expect_optarg = true
-					} else {
-						p.(*Flag).takeValue(true)
-					}
-				} else {
-					return rest, fmt.Errorf(errUnrecognizedShort, arg[1])
-				}
-			} else if arg[0] == '+' {
-				if p, ok := paramsByShort[arg[1]]; ok {
-					if p.takesArgument() {
-						return rest, fmt.Errorf(errTriedToNegateOptArg, arg[1])
-					} else {
-						p.(*Flag).takeValue(false)
-					}
-				} else {
-					return rest, fmt.Errorf(errUnrecognizedShort, arg[1])
-				}
-			} else {
-				//rest = append(rest, arg)
-				rest = addRest(rest, arg, false)
-
-			}
-		default:	//Either --blah or --foo=bar or -abc or +abc or rest
-			if arg[0] == '-' {
-				if arg[1] == '-' {
-					//Long option
-					indexOfEquals := strings.IndexByte(arg, '=')
-					if indexOfEquals < 0 {
-						long := arg[2:]
-						if p, ok := paramsByLong[long]; ok {
-							if p.takesArgument() {
-								waiting_opt = p.(*Option)
-								expect_optarg = true
-							} else {
-								p.(*Flag).takeValue(true)
-							}
-						} else {
-							return rest, fmt.Errorf(errUnrecognizedLong, long)
-						}
-					} else {
-						long := arg[2:indexOfEquals]
-						optarg := arg[indexOfEquals+1:]
-						if p, ok := paramsByLong[long]; ok {
-							if p.takesArgument() {
-								p.(*Option).addOptArg(optarg)
-							} else {
-								v, err := parseFlagOpt(long, optarg)
-								if err != nil {
-									return rest, err
-								} else {
-									p.(*Flag).takeValue(v)
-								}
-							}
-						} else {
-							return rest, fmt.Errorf(errUnrecognizedLong, long)
-						}
-					}
-				} else {
-					//clump
-					for j := 1; j < len(arg); j++ {
-						if p, ok := paramsByShort[arg[j]]; ok {
-							if p.takesArgument() {
-								if j < len(arg) - 1 {
-									//The rest of the clump is the argument to last
-									//recognized short option
-									p.(*Option).addOptArg(arg[j+1:])
-									break
-								} else {
-									//Here j == len(arg) - 1, index of last byte
-									waiting_opt = p.(*Option)
-									expect_optarg = true
-								}
-							} else {
-								p.(*Flag).takeValue(true)
-							}
-						} else {
-							return rest, fmt.Errorf(errUnrecognizedShort, arg[j])
-						}
-					}
-				}
-			} else if arg[0] == '+' {
-				//Negate clump
-				for j := 1; j < len(arg); j++ {
-					if p, ok := paramsByShort[arg[j]]; ok {
-						if p.takesArgument() {
-							return rest, fmt.Errorf(errTriedToNegateOptArg, arg[j])
-						} else {
-							p.(*Flag).takeValue(false)
-						}
-					} else {
-						return rest, fmt.Errorf(errUnrecognizedShort, arg[j])
-					}
-				}
-			} else {
-				rest = addRest(rest, arg, false)
-			}
-		}
+//Number of arguments taken each time the option is passed.
+func (o *Option)arity() int {
+	if o.nargs < 1 {
+		return 1
 	}
+	return o.nargs
+}
 
-	return rest, nil
+//Forget the opt-args passed, as if the option had never been parsed.  The
+//value of a typed option goes back to its default, or to its zero value if
+//it has none.
+func (o *Option)Clear() {
+	o.OptArg = o.Default
+	o.OptArgs = nil
+	o.Passed = false
+	o.source = Source{}
+	if o.zero != nil {
+		o.zero()
+	}
+	if o.convert != nil && o.Default != "" {
+		//An invalid default is reported by the next parse
+		o.convert(o.Default)
+	}
 }
 
-func GetOpts() ([]Rest, error) {
-	return ArgParse(os.Args)
+//The most recent opt-arg converted to an integer.
+func (o *Option)Int() (int, error) {
+	v, err := strconv.Atoi(o.OptArg)
+	if err != nil {
+		return 0, fmt.Errorf(errNotInteger, o.display(), o.masked(o.OptArg))
+	}
+	return v, nil
 }
 
-func showOptionHelp(opt option) {
-	if opt.ShortOpt == 0 {
-		//Only long option.  If we have an option with neither,
-		//that's a bug
-		if opt.LongOpt == "" {
-			panic("Long and short options are both empty")
-		}
+//The most recent opt-arg converted to a floating point number.
+func (o *Option)Float() (float64, error) {
+	v, err := strconv.ParseFloat(o.OptArg, 64)
+	if err != nil {
+		return 0, fmt.Errorf(errNotFloat, o.display(), o.masked(o.OptArg))
+	}
+	return v, nil
+}
 
-		fmt.Printf("--%-30s %s\n", opt.LongOpt, opt.Help)
-	} else {
-		if opt.LongOpt == "" {
-		//Have only short opt
-		fmt.Printf("-%-30c %s\n", opt.ShortOpt, opt.Help)
+func parseFlagOpt(flag, value string) (bool, error) {
+	if strings.EqualFold(value, "f") {
+		return false, nil
+	} else if strings.EqualFold(value, "t") {
+		return true, nil
+	}
 
-		} else {
-		//Long and short opt
-			combined := fmt.Sprintf("-%c/--%s", opt.ShortOpt, opt.LongOpt)
-			fmt.Printf("%-30s %s\n", combined, opt.Help)
-		}
+	if strings.EqualFold(value, "y") {
+		return true, nil
+	} else if strings.EqualFold(value, "n") {
+		return false, nil
 	}
-}
 
-func ShowHelp() {
-	for _, opt := range Options {
-		showOptionHelp(opt.option)
+	if strings.EqualFold(value, "no") {
+		return false, nil
+	} else if strings.EqualFold(value, "yes") {
+		return true, nil
 	}
 
-	for _, flag := range Flags {
-		showOptionHelp(flag.option)
+	if strings.EqualFold(value, "true") {
+		return true, nil
+	} else if strings.EqualFold(value, "false") {
+		return false, nil
 	}
+
+	return false, invalidValue(flag, value, errPassedOptargToFlag, flag)
 }
+
+const(
+	warnSameShortLong = "Short option -%c and long option --%c are different options"
+	warnDeprecated = "%s is deprecated"
+	warnDeprecatedMsg = "%s is deprecated, %s"
+	errArityMismatch = "Short option -%c and long option --%s disagree on whether they take an argument"
+	errUnrecognizedShort = "Unrecognized short option:  %s"
+	errUnrecognizedLong = "Unrecognized long option:  %s"
+	errSuggestion = ", did you mean --%s?"
+	errAmbiguousLong = "Ambiguous long option:  %s could be %s"
+	errTriedToNegateOptArg = "Passed negation for option expecting argument: %s"
+	errPassedOptargToFlag = "Passed non-boolean option to flag:  %s"
+	errArity = "%s requires %d arguments"
+	errMissingArgument = "Missing argument to option:  %s"
+	errCallbackPanic = "Panic in %s for %s:  %v"
+	errCallback = "Error in %s for %s:  %w"
+	errValidation = "Invalid value '%s' for %s at argument %d:  %v"
+	errValidationElsewhere = "Invalid value '%s' for %s:  %v"
+	errEmptyLongOption = "Empty option name in:  %s"
+	errNoMatch = "%s value '%s' does not match pattern"
+	errNotInteger = "Argument to option %s is not an integer:  %s"
+	errNotFloat = "Argument to option %s is not a number:  %s"
+	errNotDuration = "Argument to option %s is not a duration:  %s"
+	errNotBool = "Argument to option %s is not a boolean:  %s"
+	errNotChoice = "Argument to option %s must be one of %s:  %s"
+	errNotValue = "Argument to option %s is not valid:  %s:  %v"
+	errNotKeyValue = "Argument to option %s is not key=value:  %s"
+	errNotIP = "Argument to option %s is not an IP address:  %s"
+	errNotPrefix = "Argument to option %s is not a CIDR prefix:  %s"
+	errNotHostPort = "Argument to option %s is not host:port:  %s"
+	errNotSize = "Argument to option %s is not a size:  %s"
+	errNotTime = "Argument to option %s is not a time:  %s, expected one of %s"
+	errDuplicateKey = "Key %s given to option %s more than once:  %s"
+	errUnbalancedQuotes = "Unbalanced quotes in argument to option:  %s"
+)
@@ -1,6 +1,11 @@
 package getopts
 
 import "testing"
+import "fmt"
+import "strings"
+import "errors"
+import "io"
+import "context"
 
 //Basic recognition of short options
 func TestParseCase01(t *testing.T) {
@@ -169,3 +174,1213 @@ func TestParseCase09(t *testing.T) {
 		}
 	}
 }
+
+//Options that were never passed are reported as unset
+func TestUnsetOptions(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	NewOptionShort('o', "Output file")
+	NewOptionLong("input", "Input file")
+	_, err := ArgParse([]string{ "test", "-v" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	unset := UnsetOptions()
+	exp := []string{ "o", "input" }
+	if len(unset) != len(exp) {
+		t.Fatalf("Got %v unset, expected %v", unset, exp)
+	}
+	for i := range exp {
+		if unset[i] != exp[i] {
+			t.Fatalf("Got %s expected %s", unset[i], exp[i])
+		}
+	}
+
+	//Turning a flag off still sets it
+	ResetValues()
+	_, err = ArgParse([]string{ "test", "+v", "--input=a.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if unset := UnsetOptions(); len(unset) != 1 || unset[0] != "o" {
+		t.Fatalf("Got %v unset, expected only o", unset)
+	}
+}
+
+//Quote-aware splitting keeps quoted separators inside a field
+func TestSplitQuoteAware(t *testing.T) {
+	resetParams()
+	fields := NewOptionLong("fields", "Fields to show")
+	fields.SetSplitOn(",")
+	fields.SetSplitQuoteAware(true)
+	_, err := ArgParse([]string{ "test", `--fields=name,"a,b",id` })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	exp := []string{ "name", "a,b", "id" }
+	if len(fields.OptArgs) != len(exp) {
+		t.Fatalf("Got %v, expected %v", fields.OptArgs, exp)
+	}
+	for i := range exp {
+		if fields.OptArgs[i] != exp[i] {
+			t.Fatalf("Got %s expected %s", fields.OptArgs[i], exp[i])
+		}
+	}
+
+	_, err = ArgParse([]string{ "test", `--fields=name,"a,b` })
+	if err == nil {
+		t.Fatalf("Unbalanced quotes should be an error")
+	}
+}
+
+//Register a small set of short options for the lookup tests
+func setupLookupParams() (*Flag, *Flag, *Option) {
+	resetParams()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	all := NewFlag('a', "all", "All things")
+	file := NewOption('f', "file", "Input file")
+	return verbose, all, file
+}
+
+var lookupArgv = []string{ "test", "-av", "+a", "-ffile.txt", "-v", "rest", "-f", "other.txt" }
+
+//Array and map lookups of short options give identical results
+func TestShortLookupModes(t *testing.T) {
+	results := make([]string, 0)
+	for _, mode := range []ShortLookup{ ShortLookupMap, ShortLookupArray } {
+		verbose, all, file := setupLookupParams()
+		SetShortLookup(mode)
+		rest, err := ArgParse(lookupArgv)
+		if err != nil {
+			t.Fatalf("Error %s", err)
+		}
+		results = append(results, fmt.Sprintf("%v %d %v %d %v %v",
+			verbose.Passed, verbose.Count,
+			all.Passed, all.Count,
+			file.OptArgs, rest))
+	}
+	if results[0] != results[1] {
+		t.Fatalf("Map lookup gave %s, array lookup gave %s", results[0], results[1])
+	}
+}
+
+func benchmarkShortLookup(b *testing.B, mode ShortLookup) {
+	setupLookupParams()
+	SetShortLookup(mode)
+	for i := 0; i < b.N; i++ {
+		ArgParse(lookupArgv)
+	}
+}
+
+func BenchmarkShortLookupMap(b *testing.B) {
+	benchmarkShortLookup(b, ShortLookupMap)
+}
+
+func BenchmarkShortLookupArray(b *testing.B) {
+	benchmarkShortLookup(b, ShortLookupArray)
+}
+
+//Argument vector with many operands
+func manyOperands() []string {
+	argv := []string{ "test" }
+	for i := 0; i < 1000; i++ {
+		argv = append(argv, fmt.Sprintf("file%d.txt", i))
+	}
+	return argv
+}
+
+//Collecting operands should allocate the rest slice only once
+func TestRestAllocations(t *testing.T) {
+	resetParams()
+	argv := manyOperands()
+	allocs := testing.AllocsPerRun(10, func() {
+		ArgParse(argv)
+	})
+	if allocs > 1 {
+		t.Fatalf("Got %.0f allocations, expected 1", allocs)
+	}
+}
+
+func BenchmarkManyOperands(b *testing.B) {
+	resetParams()
+	argv := manyOperands()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ArgParse(argv)
+	}
+}
+
+//Parse a long clump of flags ending in an option with an attached argument
+func BenchmarkLargeClump(b *testing.B) {
+	resetParams()
+	clump := "-"
+	for c := 'a'; c <= 'y'; c++ {
+		NewFlagShort(c, "Flag")
+		clump += string(c)
+	}
+	NewOptionShort('z', "Option")
+	clump += "zvalue"
+	argv := []string{ "test", clump, clump, clump, clump }
+	for i := 0; i < b.N; i++ {
+		ArgParse(argv)
+	}
+}
+
+//Streaming parse gives the same operands and option values as ArgParse
+func TestArgParseFunc(t *testing.T) {
+	argv := []string{ "test", "in.txt", "-vffile.txt", "-", "--", "-v" }
+
+	resetParams()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	file := NewOption('f', "file", "Input file")
+	exp, err := ArgParse(argv)
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	expState := fmt.Sprintf("%d %v", verbose.Count, file.OptArgs)
+
+	resetParams()
+	verbose = NewFlag('v', "verbose", "Increase verbosity")
+	file = NewOption('f', "file", "Input file")
+	i := 1
+	next := func() (string, bool) {
+		if i >= len(argv) {
+			return "", false
+		}
+		i++
+		return argv[i-1], true
+	}
+	got := make([]Rest, 0)
+	err = ArgParseFunc(next, func(r Rest) {
+		got = append(got, r)
+	})
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	state := fmt.Sprintf("%d %v", verbose.Count, file.OptArgs)
+	if state != expState {
+		t.Fatalf("Got state %s expected %s", state, expState)
+	}
+	if len(got) != len(exp) {
+		t.Fatalf("Got %v expected %v", got, exp)
+	}
+	for j := range exp {
+		if got[j] != exp[j] {
+			t.Fatalf("Got %v expected %v", got[j], exp[j])
+		}
+	}
+}
+
+//A long option with an empty name is an error that names the argument
+func TestParseCase10(t *testing.T) {
+	for _, arg := range []string{ "--=x", "--=" } {
+		resetParams()
+		NewFlag('v', "verbose", "Increase verbosity")
+		_, err := ArgParse([]string{ "test", arg })
+		if err == nil {
+			t.Fatalf("%s should be an error", arg)
+		}
+		exp := "Empty option name in:  " + arg
+		if err.Error() != exp {
+			t.Fatalf("Got error '%s' expected '%s'", err, exp)
+		}
+	}
+}
+
+//A short option and single character long option with the same
+//name on different options produce a warning, or panic when unified
+func TestSameShortLong(t *testing.T) {
+	resetParams()
+	warnings := make([]string, 0)
+	OnWarning = func(msg string) {
+		warnings = append(warnings, msg)
+	}
+	NewFlagShort('v', "Increase verbosity")
+	NewOptionLong("v", "Version to use")
+	if len(warnings) != 1 {
+		t.Fatalf("Got %d warnings, expected 1", len(warnings))
+	}
+	exp := "Short option -v and long option --v are different options"
+	if warnings[0] != exp {
+		t.Fatalf("Got warning '%s' expected '%s'", warnings[0], exp)
+	}
+
+	resetParams()
+	SetUnifyShortLong(true)
+	NewFlagShort('v', "Increase verbosity")
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Registering --v after -v should panic when unified")
+		}
+	}()
+	NewOptionLong("v", "Version to use")
+}
+
+//MustParse exits with status 2 on a parse error
+func TestMustParse(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	code := -1
+	ExitFunc = func(c int) {
+		code = c
+	}
+	rest := MustParse([]string{ "test", "file.txt" })
+	if code != -1 || len(rest) != 1 {
+		t.Fatalf("Valid arguments should not exit, got %d operands", len(rest))
+	}
+	MustParse([]string{ "test", "-x" })
+	if code != 2 {
+		t.Fatalf("Got exit status %d expected 2", code)
+	}
+
+	//Exiting is not repeated by the error handling mode
+	exits := 0
+	ExitFunc = func(c int) {
+		exits++
+	}
+	SetErrorHandling(ExitOnError)
+	MustParse([]string{ "test", "-x" })
+	if exits != 1 {
+		t.Fatalf("Got %d exits expected 1", exits)
+	}
+}
+
+//Converting opt-args to numbers on demand
+func TestOptionInt(t *testing.T) {
+	resetParams()
+	jobs := NewOption('j', "jobs", "Number of jobs")
+	_, err := ArgParse([]string{ "test", "-j", "4" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	n, err := jobs.Int()
+	if err != nil || n != 4 {
+		t.Fatalf("Got %d, %v expected 4", n, err)
+	}
+	f, err := jobs.Float()
+	if err != nil || f != 4 {
+		t.Fatalf("Got %f, %v expected 4", f, err)
+	}
+
+	_, err = ArgParse([]string{ "test", "--jobs=many" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	_, err = jobs.Int()
+	if err == nil {
+		t.Fatalf("'many' should not convert to an integer")
+	}
+	exp := "Argument to option --jobs is not an integer:  many"
+	if err.Error() != exp {
+		t.Fatalf("Got error '%s' expected '%s'", err, exp)
+	}
+	_, err = jobs.Float()
+	exp = "Argument to option --jobs is not a number:  many"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error '%v' expected '%s'", err, exp)
+	}
+}
+
+//'--' after an option expecting an argument is the argument by default
+//and ends parsing with SetTerminatorAlwaysWins
+func TestParseCase11(t *testing.T) {
+	resetParams()
+	file := NewOption('f', "file", "Input file")
+	rest, err := ArgParse([]string{ "test", "--file", "--", "x" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if file.OptArg != "--" || len(rest) != 1 || rest[0].AfterDashes {
+		t.Fatalf("Got %s and %v, expected -- as argument to --file", file.OptArg, rest)
+	}
+
+	resetParams()
+	NewOption('f', "file", "Input file")
+	SetTerminatorAlwaysWins(true)
+	_, err = ArgParse([]string{ "test", "--file", "--", "x" })
+	if err == nil || err.Error() != "Missing argument to option:  --file" {
+		t.Fatalf("Got error %v, expected missing argument", err)
+	}
+}
+
+//-W introduces a long option when the W extension is on
+func TestParseCase12(t *testing.T) {
+	resetParams()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	file := NewOption('f', "file", "Input file")
+	SetWExtension('W')
+	_, err := ArgParse([]string{ "test", "-W", "verbose", "-Wfile=x" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if !verbose.Passed {
+		t.Fatalf("-W verbose should set verbose")
+	}
+	if file.OptArg != "x" {
+		t.Fatalf("-Wfile=x should set file to x, got %s", file.OptArg)
+	}
+
+	_, err = ArgParse([]string{ "test", "-vWverbose=false", "-W", "file", "y" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if verbose.Passed || file.OptArg != "y" {
+		t.Fatalf("Got verbose %v and file %s", verbose.Passed, file.OptArg)
+	}
+}
+
+//Global options are parsed up to the first operand, which starts
+//the remainder
+func TestParseGlobals(t *testing.T) {
+	resetParams()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	argv := []string{ "test", "-v", "subcmd", "--plugin-flag" }
+	done, rest, err := ParseGlobals(argv)
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if !verbose.Passed {
+		t.Fatalf("-v should set verbose")
+	}
+	if done != 2 || len(rest) != 2 || rest[0] != "subcmd" || rest[1] != "--plugin-flag" {
+		t.Fatalf("Got %d and %v, expected remainder from subcmd", done, rest)
+	}
+
+	_, _, err = ParseGlobals([]string{ "test", "--unknown", "subcmd" })
+	if err == nil {
+		t.Fatalf("Unrecognized option before operand should be an error")
+	}
+}
+
+//An operand from an alias stops ParseGlobals without giving back the alias
+func TestParseGlobalsAlias(t *testing.T) {
+	resetParams()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	RegisterAlias('a', "", []string{ "-v", "sub", "-q" })
+	done, rest, err := ParseGlobals([]string{ "t", "-a", "x", "y" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if !verbose.Passed {
+		t.Fatalf("-v from the alias should set verbose")
+	}
+	if done != 1 || fmt.Sprint(rest) != "[sub -q x y]" {
+		t.Fatalf("Got %d and %v, expected 1 and [sub -q x y]", done, rest)
+	}
+}
+
+//Single dash long options, with clumps taking precedence
+func TestParseCase13(t *testing.T) {
+	resetParams()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	all := NewFlag('a', "all", "All things")
+	av := NewFlagLong("av", "Audio and video")
+	file := NewOptionLong("file", "Input file")
+	SetSingleDashLong(true)
+	_, err := ArgParse([]string{ "test", "-verbose", "-file=x" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if !verbose.Passed || all.Passed || file.OptArg != "x" {
+		t.Fatalf("-verbose should set only verbose and -file=x should set file")
+	}
+
+	resetParams()
+	verbose = NewFlag('v', "verbose", "Increase verbosity")
+	all = NewFlag('a', "all", "All things")
+	av = NewFlagLong("av", "Audio and video")
+	SetSingleDashLong(true)
+	_, err = ArgParse([]string{ "test", "-av" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if !verbose.Passed || !all.Passed || av.Passed {
+		t.Fatalf("-av should be read as a clump")
+	}
+}
+
+//Panics in callbacks become errors when recovering callbacks
+func TestRecoverCallbacks(t *testing.T) {
+	resetParams()
+	file := NewOption('f', "file", "Input file")
+	file.Action = func(arg string) {
+		panic("cannot open " + arg)
+	}
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	verbose.OnFalse = func() {
+		panic("cannot be quiet")
+	}
+	SetRecoverCallbacks(true)
+	_, err := ArgParse([]string{ "test", "--file", "x.txt" })
+	exp := "Panic in action for --file:  cannot open x.txt"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v, expected '%s'", err, exp)
+	}
+	_, err = ArgParse([]string{ "test", "+v" })
+	exp = "Panic in OnFalse for --verbose:  cannot be quiet"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v, expected '%s'", err, exp)
+	}
+}
+
+//Errors from context callbacks stop parsing, and nothing is called once
+//the context is cancelled
+func TestContextCallbacks(t *testing.T) {
+	resetParams()
+	errNoFile := errors.New("no such file")
+	file := NewOption('f', "file", "Input file")
+	file.ActionContext = func(ctx context.Context, arg string) error {
+		if arg == "missing.txt" {
+			return errNoFile
+		}
+		return nil
+	}
+	calls := 0
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	verbose.OnTrueContext = func(ctx context.Context) error {
+		calls++
+		return nil
+	}
+	rest, err := ArgParse([]string{ "test", "-f", "missing.txt", "-v", "a" })
+	exp := "Error in action for --file:  no such file"
+	if err == nil || err.Error() != exp || !errors.Is(err, errNoFile) {
+		t.Fatalf("Got error %v, expected '%s'", err, exp)
+	}
+	if len(rest) != 0 || calls != 0 {
+		t.Fatalf("Parsing should stop at the error, got %v and %d calls", rest, calls)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = ArgParseContext(ctx, []string{ "test", "-v", "-v" })
+	if err != nil || calls != 2 {
+		t.Fatalf("Got error %v and %d calls, expected 2 calls", err, calls)
+	}
+	cancel()
+	_, err = ArgParseContext(ctx, []string{ "test", "-v" })
+	if !errors.Is(err, context.Canceled) || calls != 2 {
+		t.Fatalf("Got error %v and %d calls, expected cancellation", err, calls)
+	}
+	_, err = ArgParseContext(ctx, []string{ "test", "b" })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Got error %v, expected cancellation without callbacks due", err)
+	}
+}
+
+//Deferred callbacks run only once the whole command line has parsed
+func TestDeferCallbacks(t *testing.T) {
+	resetParams()
+	calls := make([]string, 0)
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	file := NewOption('f', "file", "Input file")
+	file.Action = func(arg string) {
+		calls = append(calls, fmt.Sprintf("file %s verbose %t", arg, verbose.Passed))
+	}
+	verbose.OnTrue = func() {
+		calls = append(calls, "verbose")
+	}
+	SetDeferCallbacks(true)
+	_, err := ArgParse([]string{ "test", "-f", "a.txt", "-v", "-x" })
+	if err == nil || len(calls) != 0 {
+		t.Fatalf("Got error %v and calls %v, expected no calls", err, calls)
+	}
+	_, err = ArgParse([]string{ "test", "-f", "a.txt", "-v" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	exp := []string{ "file a.txt verbose true", "verbose" }
+	if fmt.Sprint(calls) != fmt.Sprint(exp) {
+		t.Fatalf("Got calls %v, expected %v", calls, exp)
+	}
+}
+
+//A number given to a flag sets its count
+func TestParseCase14(t *testing.T) {
+	resetParams()
+	debug := NewFlag('d', "debug", "Debug level")
+	_, err := ArgParse([]string{ "test", "-d", "--debug=3" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if debug.Count != 3 || !debug.Passed {
+		t.Fatalf("--debug=3 should set count to 3, got %d", debug.Count)
+	}
+	_, err = ArgParse([]string{ "test", "--debug=true" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if debug.Count != 4 || !debug.Passed {
+		t.Fatalf("--debug=true should increment count to 4, got %d", debug.Count)
+	}
+	_, err = ArgParse([]string{ "test", "--debug=-2" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if debug.Count != -2 || debug.Passed {
+		t.Fatalf("--debug=-2 should set count to -2, got %d", debug.Count)
+	}
+}
+
+//Raw arguments are recorded verbatim
+func TestRawArgs(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	NewOption('f', "file", "Input file")
+	SetCaptureRaw(true)
+	argv := []string{ "test", "-vffile.txt", "in.txt", "--verbose=false", "--", "-v" }
+	_, err := ArgParse(argv)
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	raw := RawArgs()
+	if len(raw) != len(argv) - 1 {
+		t.Fatalf("Got %v expected %v", raw, argv[1:])
+	}
+	for i := range raw {
+		if raw[i] != argv[i+1] {
+			t.Fatalf("Got %v expected %v", raw, argv[1:])
+		}
+	}
+}
+
+//Strict registration rejects a short flag and long option that
+//plausibly name the same thing
+func TestStrictRegistration(t *testing.T) {
+	resetParams()
+	SetStrictRegistration(true)
+	NewFlagShort('f', "Force")
+	NewFlagLong("force", "Force")
+	NewOptionLong("output", "Output file")
+	defer func() {
+		r := recover()
+		exp := "Short option -f and long option --file disagree on whether they take an argument"
+		if r != exp {
+			t.Fatalf("Got panic %v expected '%s'", r, exp)
+		}
+	}()
+	NewOptionLong("file", "Input file")
+}
+
+//Opt-args must match the option's pattern
+func TestPattern(t *testing.T) {
+	resetParams()
+	version := NewOptionLong("version", "Version to install")
+	err := version.SetPatternString(`^\d+\.\d+\.\d+$`)
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	_, err = ArgParse([]string{ "test", "--version", "1.2.3" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	_, err = ArgParse([]string{ "test", "--version", "latest" })
+	exp := "--version value 'latest' does not match pattern"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v expected '%s'", err, exp)
+	}
+	if version.OptArg != "1.2.3" {
+		t.Fatalf("Rejected value should not be kept, got %s", version.OptArg)
+	}
+	if version.SetPatternString("(") == nil {
+		t.Fatalf("Invalid pattern should be an error")
+	}
+}
+
+//Arguments attached to short options, with and without '='
+func TestParseCase15(t *testing.T) {
+	cases := []struct {
+		equal	bool
+		argv	[]string
+		exp	string
+	}{
+		{ true, []string{ "test", "-ofile" }, "file" },
+		{ true, []string{ "test", "-o=file" }, "file" },
+		{ false, []string{ "test", "-o=file" }, "=file" },
+		{ true, []string{ "test", "-xof" }, "f" },
+		{ true, []string{ "test", "-xo", "file" }, "file" },
+	}
+	for _, c := range cases {
+		resetParams()
+		NewFlagShort('x', "Extract")
+		out := NewOptionShort('o', "Output file")
+		SetEqualInShort(c.equal)
+		_, err := ArgParse(c.argv)
+		if err != nil {
+			t.Fatalf("Error %s", err)
+		}
+		if out.OptArg != c.exp {
+			t.Fatalf("%v: Got %s expected %s", c.argv, out.OptArg, c.exp)
+		}
+	}
+}
+
+//An empty argument vector has no options or operands
+func TestParseCase16(t *testing.T) {
+	resetParams()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	for _, argv := range [][]string{ nil, {} } {
+		rest, err := ArgParse(argv)
+		if err != nil {
+			t.Fatalf("Error %s", err)
+		}
+		if len(rest) != 0 || verbose.Passed {
+			t.Fatalf("Empty argv should give nothing, got %v", rest)
+		}
+	}
+	_, err := ArgParse([]string{ "/usr/bin/mytool" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if CommandLine.programName() != "mytool" {
+		t.Fatalf("Got program name %s expected mytool", CommandLine.programName())
+	}
+}
+
+//A rest-of-line option takes every following word in ParseString
+func TestRestOfLine(t *testing.T) {
+	resetParams()
+	all := NewFlag('a', "all", "Commit all changes")
+	message := NewOption('m', "message", "Commit message")
+	message.SetRestOfLine(true)
+	SetCaptureRaw(true)
+	rest, err := ParseString("commit -a -m hello world")
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	raw := []string{ "commit", "-a", "-m", "hello", "world" }
+	if fmt.Sprint(RawArgs()) != fmt.Sprint(raw) {
+		t.Fatalf("Got raw arguments %q expected %q", RawArgs(), raw)
+	}
+	if !all.Passed || message.OptArg != "hello world" {
+		t.Fatalf("Got message '%s' expected 'hello world'", message.OptArg)
+	}
+	if len(rest) != 1 || rest[0].Argument != "commit" {
+		t.Fatalf("Got %v expected only commit", rest)
+	}
+
+	_, err = ParseString("--message=fix -- the -v flag")
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if message.OptArg != "fix -- the -v flag" {
+		t.Fatalf("Got message '%s'", message.OptArg)
+	}
+}
+
+//ResetValues clears values but keeps registrations
+func TestResetValues(t *testing.T) {
+	resetParams()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	file := NewOption('f', "file", "Input file")
+	_, err := ArgParse([]string{ "test", "-vv", "-f", "x.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	ResetValues()
+	if verbose.Passed || verbose.Count != 0 {
+		t.Fatalf("Flag should be cleared, got count %d", verbose.Count)
+	}
+	if file.Passed || file.OptArg != "" || len(file.OptArgs) != 0 {
+		t.Fatalf("Option should be cleared, got %v", file.OptArgs)
+	}
+	_, err = ArgParse([]string{ "test", "-v", "--file=y.txt" })
+	if err != nil {
+		t.Fatalf("Options should still be registered, got %s", err)
+	}
+	if verbose.Count != 1 || len(file.OptArgs) != 1 {
+		t.Fatalf("Got count %d and %v after reset", verbose.Count, file.OptArgs)
+	}
+}
+
+//ResetValues puts typed values and bound variables back to their defaults
+func TestResetTypedValues(t *testing.T) {
+	resetParams()
+	jobs := NewIntOption('j', "jobs", "Parallel jobs")
+	jobs.SetDefault(2)
+	retries := NewIntOption('r', "retries", "Retries")
+	var level int
+	IntVar(&level, 'l', "level", 3, "Compression level")
+	_, err := ArgParse([]string{ "test", "-j", "8", "-r", "5", "-l", "9" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	ResetValues()
+	if jobs.Value != 2 || jobs.OptArg != "2" {
+		t.Fatalf("Got %d and '%s' expected the default 2", jobs.Value, jobs.OptArg)
+	}
+	if retries.Value != 0 {
+		t.Fatalf("Got %d expected 0 without a default", retries.Value)
+	}
+	if level != 3 {
+		t.Fatalf("Got %d expected the bound default 3", level)
+	}
+}
+
+//Options taking a fixed number of arguments
+func TestParseCase17(t *testing.T) {
+	resetParams()
+	point := NewOption('p', "point", "Coordinates")
+	point.SetArity(2)
+	rest, err := ArgParse([]string{ "test", "--point", "1", "2", "-p3", "4", "--point=5", "6", "x" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	exp := []string{ "1", "2", "3", "4", "5", "6" }
+	if fmt.Sprint(point.OptArgs) != fmt.Sprint(exp) {
+		t.Fatalf("Got %v expected %v", point.OptArgs, exp)
+	}
+	if len(rest) != 1 || rest[0].Argument != "x" {
+		t.Fatalf("Got %v expected only x", rest)
+	}
+
+	_, err = ArgParse([]string{ "test", "--point", "1" })
+	if err == nil || err.Error() != "--point requires 2 arguments" {
+		t.Fatalf("Got error %v expected --point requires 2 arguments", err)
+	}
+}
+
+//Flags negated without being passed are reported
+func TestNegativeFlags(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	NewFlag('a', "all", "All things")
+	NewFlagShort('x', "Extract")
+	_, err := ArgParse([]string{ "test", "+v", "-a", "+a", "-x" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	negative := NegativeFlags()
+	if len(negative) != 1 || negative[0] != "verbose" {
+		t.Fatalf("Got %v expected [verbose]", negative)
+	}
+}
+
+//An option consuming the remainder takes every following argument
+func TestConsumesRemainder(t *testing.T) {
+	resetParams()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	exec := NewOptionLong("exec", "Command to run")
+	exec.SetConsumesRemainder(true)
+	rest, err := ArgParse([]string{ "test", "-v", "--exec", "ls", "-la", "/tmp" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	exp := []string{ "ls", "-la", "/tmp" }
+	if fmt.Sprint(exec.OptArgs) != fmt.Sprint(exp) || exec.OptArg != "ls" {
+		t.Fatalf("Got %v expected %v", exec.OptArgs, exp)
+	}
+	if !verbose.Passed || len(rest) != 0 {
+		t.Fatalf("Got %v, expected no operands", rest)
+	}
+}
+
+//Registering an option with neither short nor long name panics
+func TestNoName(t *testing.T) {
+	resetParams()
+	defer func() {
+		r := recover()
+		if r != "Option must have a short or long name" {
+			t.Fatalf("Got panic %v", r)
+		}
+		if len(CommandLine.params) != 0 {
+			t.Fatalf("Nameless option should not be registered")
+		}
+	}()
+	NewFlag(0, "", "Unreachable")
+}
+
+//Options not passed take their default, which help shows
+func TestDefault(t *testing.T) {
+	resetParams()
+	output := NewOption('o', "output", "Output file")
+	output.Default = "out.txt"
+	_, err := ArgParse([]string{ "test" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if output.OptArg != "out.txt" || output.Passed || len(output.OptArgs) != 0 {
+		t.Fatalf("Got %s, expected default without being passed", output.OptArg)
+	}
+	_, err = ArgParse([]string{ "test", "-o", "other.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if output.OptArg != "other.txt" {
+		t.Fatalf("Got %s, expected other.txt", output.OptArg)
+	}
+	output.Clear()
+	if output.OptArg != "out.txt" {
+		t.Fatalf("Clear should restore the default, got %s", output.OptArg)
+	}
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	if b.String() != "-o/--output Output file (default: out.txt)\n" {
+		t.Fatalf("Got help %s", b.String())
+	}
+}
+
+//Unambiguous prefixes of long options are accepted when enabled
+func TestAbbreviations(t *testing.T) {
+	resetParams()
+	verbose := NewFlagLong("verbose", "Increase verbosity")
+	NewFlagLong("verbatim", "Copy input unchanged")
+	file := NewOption('f', "file", "File to read")
+	file2 := NewOptionLong("file-list", "File of files")
+	_, err := ArgParse([]string{ "test", "--verbo" })
+	if err == nil {
+		t.Fatalf("Abbreviations should be off by default")
+	}
+	SetAbbreviations(true)
+	_, err = ArgParse([]string{ "test", "--verbo", "--file=a.txt", "--file-l", "b.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if !verbose.Passed || file.OptArg != "a.txt" || file2.OptArg != "b.txt" {
+		t.Fatalf("Got verbose %v file %s file-list %s", verbose.Passed, file.OptArg, file2.OptArg)
+	}
+	_, err = ArgParse([]string{ "test", "--verb" })
+	exp := "Ambiguous long option:  verb could be --verbose, --verbatim"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v, expected '%s'", err, exp)
+	}
+}
+
+//--no-name negates a flag when enabled, and help shows it
+func TestNegateWithNo(t *testing.T) {
+	resetParams()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	NewOptionLong("file", "File to read")
+	SetNegateWithNo(true)
+	_, err := ArgParse([]string{ "test", "-vv", "--no-verbose" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if verbose.Passed || verbose.Count != 1 {
+		t.Fatalf("Got %v and count %d, expected negated", verbose.Passed, verbose.Count)
+	}
+	_, err = ArgParse([]string{ "test", "--no-file" })
+	if err == nil || err.Error() != "Unrecognized long option:  no-file" {
+		t.Fatalf("Got error %v, expected --no-file to be unrecognized", err)
+	}
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	if !strings.HasPrefix(b.String(), "-v/--[no-]verbose Increase verbosity\n") {
+		t.Fatalf("Got help %s", b.String())
+	}
+}
+
+//In POSIX mode the first operand ends option parsing
+func TestParseCase18(t *testing.T) {
+	resetParams()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	file := NewOptionShort('f', "File to read")
+	SetScanMode(ScanPOSIX)
+	rest, err := ArgParse([]string{ "test", "-f", "in.txt", "run", "-v", "--", "x" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if verbose.Passed || file.OptArg != "in.txt" {
+		t.Fatalf("Got verbose %v and file %s", verbose.Passed, file.OptArg)
+	}
+	exp := []string{ "run", "-v", "--", "x" }
+	if len(rest) != len(exp) {
+		t.Fatalf("Got %v expected %v", rest, exp)
+	}
+	for i := range exp {
+		if rest[i].Argument != exp[i] || rest[i].AfterDashes {
+			t.Fatalf("Got %v expected %v", rest, exp)
+		}
+	}
+}
+
+//Operands record their position in argv, with options mixed in
+func TestRestIndex(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	NewOptionShort('f', "File to read")
+	SetScanMode(ScanPermute)
+	rest, err := ArgParse([]string{ "test", "a", "-v", "-f", "x", "b", "--", "c" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	exp := []int{ 1, 5, 7 }
+	if len(rest) != len(exp) {
+		t.Fatalf("Got %v", rest)
+	}
+	for i := range exp {
+		if rest[i].Index != exp[i] {
+			t.Fatalf("Got %v, expected indexes %v", rest, exp)
+		}
+	}
+	rest, err = ParseString("-v a b")
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if rest[0].Index != 2 || rest[1].Index != 3 {
+		t.Fatalf("Got %v, expected indexes after the options", rest)
+	}
+}
+
+//An optional argument is used only when attached, otherwise the implicit
+//value is
+func TestOptionalArg(t *testing.T) {
+	resetParams()
+	color := NewOption('c', "color", "When to use color")
+	color.SetOptionalArg("auto")
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	cases := []struct {
+		args	[]string
+		exp	string
+		rest	int
+	}{
+		{ []string{ "test", "--color" }, "auto", 0 },
+		{ []string{ "test", "--color=never" }, "never", 0 },
+		{ []string{ "test", "--color", "never" }, "auto", 1 },
+		{ []string{ "test", "-c" }, "auto", 0 },
+		{ []string{ "test", "-calways" }, "always", 0 },
+		{ []string{ "test", "-vc", "x" }, "auto", 1 },
+	}
+	for _, c := range cases {
+		color.Clear()
+		rest, err := ArgParse(c.args)
+		if err != nil {
+			t.Fatalf("Error %s", err)
+		}
+		if color.OptArg != c.exp || len(rest) != c.rest {
+			t.Fatalf("%v: got %s and %v, expected %s and %d operands",
+				c.args, color.OptArg, rest, c.exp, c.rest)
+		}
+	}
+	if !verbose.Passed {
+		t.Fatalf("Flag before the optional option in a clump was not set")
+	}
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	if !strings.HasPrefix(b.String(), "-c/--color[=auto] When to use color\n") {
+		t.Fatalf("Got help %s", b.String())
+	}
+}
+
+//Short options can be any character, alone, in clumps, or negated
+func TestRuneShortOptions(t *testing.T) {
+	resetParams()
+	umlaut := NewFlag('ä', "umlaut", "Use umlauts")
+	verbose := NewFlagShort('v', "Increase verbosity")
+	name := NewOptionShort('名', "Name to use")
+	_, err := ArgParse([]string{ "test", "-ä", "-vä名太郎" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if umlaut.Count != 2 || !verbose.Passed || name.OptArg != "太郎" {
+		t.Fatalf("Got count %d, verbose %v, name %s", umlaut.Count, verbose.Passed, name.OptArg)
+	}
+	_, err = ArgParse([]string{ "test", "+ä", "-名", "花子" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if umlaut.Passed || name.OptArg != "花子" {
+		t.Fatalf("Got umlaut %v and name %s", umlaut.Passed, name.OptArg)
+	}
+	_, err = ArgParse([]string{ "test", "-vö" })
+	if err == nil || err.Error() != "Unrecognized short option:  ö" {
+		t.Fatalf("Got error %v, expected ö to be unrecognized", err)
+	}
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	if !strings.HasPrefix(b.String(), "-ä/--umlaut Use umlauts\n-v          Increase") {
+		t.Fatalf("Got help %s", b.String())
+	}
+}
+
+//Byte short options still register through the deprecated wrappers
+func TestByteShortOptions(t *testing.T) {
+	resetParams()
+	var v, o byte = 'v', 'o'
+	verbose := NewFlagByte(v, "verbose", "Increase verbosity")
+	output := NewOptionShortByte(o, "Output file")
+	name := NewOptionShort('名', "Name to use")
+	_, err := ArgParse([]string{ "test", "-v", "-o", "x.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if !verbose.Passed || output.OptArg != "x.txt" {
+		t.Fatalf("Got verbose %v and output %s", verbose.Passed, output.OptArg)
+	}
+	if verbose.ShortByte() != 'v' || name.ShortByte() != 0 {
+		t.Fatalf("Got %c and %d from ShortByte", verbose.ShortByte(), name.ShortByte())
+	}
+}
+
+//Deprecated options still work, warn once per parse, and are marked in help
+func TestDeprecate(t *testing.T) {
+	resetParams()
+	colour := NewOptionLong("colour", "When to use color")
+	colour.Deprecate("use --color")
+	old := NewFlag('q', "quiet", "Print less")
+	old.Deprecate("")
+	warnings := make([]string, 0)
+	OnWarning = func(msg string) {
+		warnings = append(warnings, msg)
+	}
+	_, err := ArgParse([]string{ "test", "--colour=never", "-qq", "--colour", "auto" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if colour.OptArg != "auto" || old.Count != 2 {
+		t.Fatalf("Got colour %s and count %d", colour.OptArg, old.Count)
+	}
+	exp := []string{ "--colour is deprecated, use --color", "--quiet is deprecated" }
+	if len(warnings) != len(exp) || warnings[0] != exp[0] || warnings[1] != exp[1] {
+		t.Fatalf("Got warnings %q expected %q", warnings, exp)
+	}
+	warnings = warnings[:0]
+	for range 2 {
+		if err := Set("colour", "always"); err != nil {
+			t.Fatalf("Error %s", err)
+		}
+	}
+	if len(warnings) != 2 || warnings[1] != exp[0] {
+		t.Fatalf("Got warnings %q expected one for each Set", warnings)
+	}
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	if b.String() != "--colour   When to use color (deprecated, use --color)\n" +
+		"-q/--quiet Print less (deprecated)\n" {
+		t.Fatalf("Got help %s", b.String())
+	}
+}
+
+//Extra long names set the same option, and help shows them when asked
+func TestAddLongName(t *testing.T) {
+	resetParams()
+	color := NewOption('c', "color", "When to use color")
+	verbose := NewFlagLong("verbose", "Increase verbosity")
+	AddLongName(color, "colour")
+	AddLongName(verbose, "loud")
+	SetNegateWithNo(true)
+	_, err := ArgParse([]string{ "test", "--colour=never", "--loud", "--loud", "--no-loud" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if color.OptArg != "never" || verbose.Count != 1 {
+		t.Fatalf("Got color %s and count %d", color.OptArg, verbose.Count)
+	}
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	if !strings.HasPrefix(b.String(), "-c/--color ") {
+		t.Fatalf("Got help %s", b.String())
+	}
+	b.Reset()
+	SetHelpShowLongNames(true)
+	CommandLine.writeHelp(&b)
+	if !strings.HasPrefix(b.String(), "-c/--color, --colour   When to use color\n") {
+		t.Fatalf("Got help %s", b.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Adding a taken long name should panic")
+		}
+	}()
+	AddLongName(verbose, "color")
+}
+
+//Extra long names work as config keys, abbreviations, and suggestions
+func TestAddLongNameLookups(t *testing.T) {
+	resetParams()
+	color := NewOption('c', "color", "When to use color")
+	AddLongName(color, "colour")
+	NewFlagLong("verbose", "Increase verbosity")
+	path := writeConfig(t, "tool.conf", "colour = never\n")
+	if err := LoadConfig(path); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if _, err := ArgParse([]string{ "test" }); err != nil || color.OptArg != "never" {
+		t.Fatalf("Got color %s and error %v, expected never from the config", color.OptArg, err)
+	}
+	ResetValues()
+	SetAbbreviations(true)
+	if _, err := ArgParse([]string{ "test", "--colou=auto" }); err != nil || color.OptArg != "auto" {
+		t.Fatalf("Got color %s and error %v, expected auto", color.OptArg, err)
+	}
+	if _, err := ArgParse([]string{ "test", "--col=auto" }); err != nil {
+		t.Fatalf("A prefix of both names of one option should not be ambiguous:  %s", err)
+	}
+	SetAbbreviations(false)
+	var unknown *ErrUnknownOption
+	_, err := ArgParse([]string{ "test", "--colout=auto" })
+	if !errors.As(err, &unknown) || unknown.Suggestion != "colour" {
+		t.Fatalf("Got error %v, expected a suggestion of --colour", err)
+	}
+}
+
+//The error handling mode decides whether a parse error is returned, exits,
+//or panics
+func TestErrorHandling(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	SetAutoHelp()
+	SetHelpOutput(io.Discard)
+	code := -1
+	ExitFunc = func(c int) {
+		code = c
+	}
+	if _, err := ArgParse([]string{ "test", "-x" }); err == nil || code != -1 {
+		t.Fatalf("ContinueOnError should return the error, got %v and status %d", err, code)
+	}
+
+	SetErrorHandling(ExitOnError)
+	ArgParse([]string{ "test", "-x" })
+	if code != 2 {
+		t.Fatalf("Got exit status %d expected 2", code)
+	}
+	ArgParse([]string{ "test", "--help" })
+	if code != 0 {
+		t.Fatalf("Got exit status %d after help, expected 0", code)
+	}
+
+	SetErrorHandling(PanicOnError)
+	defer func() {
+		var unknown *ErrUnknownOption
+		if err, ok := recover().(error); !ok || !errors.As(err, &unknown) {
+			t.Fatalf("Expected a panic with the parse error")
+		}
+	}()
+	ArgParse([]string{ "test", "-x" })
+}
+
+//Set goes through the same conversion, validation, and callbacks as parsing
+func TestSet(t *testing.T) {
+	resetParams()
+	var jobs int
+	IntVar(&jobs, 'j', "jobs", 1, "Number of jobs")
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	files := make([]string, 0)
+	file := NewOption('f', "file", "Input file")
+	file.Action = func(arg string) {
+		files = append(files, arg)
+	}
+	file.Validate = func(arg string) error {
+		if arg == "" {
+			return errors.New("must not be empty")
+		}
+		return nil
+	}
+	if err := Set("jobs", "4"); err != nil || jobs != 4 {
+		t.Fatalf("Got jobs %d and error %v, expected 4", jobs, err)
+	}
+	if err := Set("v", "3"); err != nil || verbose.Count != 3 {
+		t.Fatalf("Got count %d and error %v, expected 3", verbose.Count, err)
+	}
+	if err := Set("--file", "a.txt"); err != nil || len(files) != 1 || !file.Passed {
+		t.Fatalf("Got files %v and error %v", files, err)
+	}
+	if err := Set("jobs", "four"); err == nil {
+		t.Fatalf("Expected an error for a non-integer")
+	}
+	if err := Set("file", ""); err == nil || err.Error() != "Invalid value '' for --file:  must not be empty" {
+		t.Fatalf("Got error %v", err)
+	}
+	var unknown *ErrUnknownOption
+	if err := Set("verbos", "true"); !errors.As(err, &unknown) || unknown.Suggestion != "verbose" {
+		t.Fatalf("Got error %v, expected an unknown option", err)
+	}
+}
@@ -0,0 +1,1829 @@
+package getopts
+
+import "os"
+import "fmt"
+import "errors"
+import "strconv"
+import "strings"
+import "unicode/utf8"
+import "io"
+import "text/template"
+import "path/filepath"
+import "context"
+
+//A set of flags and options and the settings used to parse them.  Separate
+//parsers share nothing, so a library can define its own command line
+//without touching the program's, and tests can run in parallel.  The
+//package level functions use CommandLine.
+type Parser struct {
+	//Registered options, in registration order
+	Options	[]*Option
+	//Registered flags, in registration order
+	Flags	[]*Flag
+	//If present, called with each operand; the operand is kept only
+	//if it returns true.
+	OnRestArg	func(arg string, afterDash bool) bool
+	//If present, called with diagnostics about suspicious but legal use of
+	//the parser.  Otherwise they are printed to standard error.
+	OnWarning	func(msg string)
+
+	paramsByShort	map[rune]parameter
+	paramsByLong	map[string]parameter
+	//Every registered flag and option, in registration order.
+	params	[]parameter
+
+	shortLookupMode	ShortLookup
+	//Array copy of paramsByShort, built on first parse.  shortTableSize is the
+	//number of short options it was built from, so registering another short
+	//option causes it to be rebuilt.
+	shortTable	*[256]parameter
+	shortTableSize	int
+
+	//Whether options may follow operands
+	scanMode	ScanMode
+	//Index of the argument being parsed, counting the program name as 0
+	argIndex	int
+	//Index of '--' in the last parse, or -1
+	dashIndex	int
+	//Number of flags and options passed in the last parse
+	optionsParsed	int
+	//Whether unrecognized options are collected instead of rejected
+	lenient	bool
+	//Unrecognized options collected in the last parse
+	unknown	[]string
+	//Whether parsing goes on after an error to find the rest
+	collectErrors	bool
+	//Errors found so far in the last parse, when collecting them
+	errs	[]error
+	//Whether @file arguments are replaced by the arguments in file
+	responseFiles	bool
+	//What parsing does when it fails
+	errorHandling	ErrorHandling
+	//Whether help and errors are colored
+	color	ColorMode
+	//Name, one line description, usage lines, and closing text given
+	//for help
+	program	string
+	summary	string
+	synopsisLines	[]string
+	epilog	string
+	//Titles of the help sections, in the order they are shown
+	helpSections	[]string
+	//If not nil, renders help instead of the default layout
+	helpTemplate	*template.Template
+	//Examples for the help template
+	helpExamples	[]ManExample
+	//Whether help shows every long option of an option, not just the first
+	helpShowLongNames	bool
+	//Deprecated options already warned about in the last parse
+	warnedDeprecated	map[*option]bool
+	//Whether '--' ends option parsing even where an opt-arg is expected.
+	terminatorAlwaysWins	bool
+	//Short option that introduces a long option, or 0 if none.
+	wExtension	rune
+	//Whether '=' may separate a short option from its attached argument.
+	equalInShort	bool
+	//Whether long options may be passed with a single dash.
+	singleDashLong	bool
+	//Whether registration checks for mismatched short and long options.
+	strictRegistration	bool
+	//Whether short options and single character long options share a namespace.
+	unifyShortLong	bool
+	//Whether panics in callbacks are returned as errors.
+	recoverCallbacks	bool
+	//Context given to ParseContext while it runs, otherwise nil.
+	ctx	context.Context
+	//Whether callbacks wait until the whole command line has parsed, and
+	//those waiting in the current parse
+	deferCallbacks	bool
+	deferred	[]func() error
+
+	//Whether to record the arguments seen by the last parse.
+	captureRaw	bool
+	rawArgs	[]string
+	//Program name from argv[0] of the last parse.
+	argvName	string
+
+	//Produces the remaining words while ParseString is running, otherwise nil.
+	lineNext	func() (string, bool)
+	//Option that takes the remaining arguments, once it has been passed.
+	remainderOpt	*Option
+	//Whether ParseGlobals is running, so the first operand stops parsing;
+	//then that operand and the words of its expansion not yet parsed, and
+	//the position of the argument it came from
+	stopAtOperand	bool
+	heldBack	[]string
+	heldIndex	int
+
+	//Prefix of environment variables consulted for options not passed on the
+	//command line.  Empty means the environment is not consulted.
+	envPrefix	string
+
+	helpSort	HelpSort
+	operandSpec	[]OperandSpec
+	//Values loaded from a config file
+	config	configValues
+	//Option naming a config file to load after parsing
+	configOpt	*Option
+	//Path of the config file last loaded
+	configPath	string
+	//Where values assigned now come from
+	origin	Source
+
+	//Flags added by SetAutoHelp and SetVersion, or nil
+	helpFlag	*Flag
+	versionFlag	*Flag
+	version	string
+
+	//Whether --no-name negates the flag --name
+	negateWithNo	bool
+	//Whether long options may be abbreviated
+	abbreviations	bool
+	//Largest edit distance for suggesting a long option, or 0 for none
+	suggestDistance	int
+
+	//Where ShowHelp writes, or standard output if nil
+	helpOutput	io.Writer
+	//Width help is wrapped to, or 0 to use the terminal's
+	helpWidth	int
+
+	//Number of operands after '--' in the current parse.
+	afterDashCount	int
+	//Limits on the number of operands after '--'.  A negative maximum is
+	//no limit.
+	minAfterDash	int
+	maxAfterDash	int
+	//Called after parsing, once the built-in checks pass.
+	finalValidator	func(*Parser) error
+
+	countLinks	[]countLink
+	groups	[]*ExclusiveGroup
+	//Copy parsed values into struct fields registered by Bind
+	bound	[]func()
+	//Put bound variables back as they were when bound, for ResetValues
+	unbound	[]func()
+
+	//Expansions of aliases, by the short or long option that invokes them.
+	aliasesByShort	map[rune][]string
+	aliasesByLong	map[string][]string
+}
+
+//A parser with no flags or options and default settings.
+func NewParser() *Parser {
+	return &Parser{
+		Options:	make([]*Option, 0),
+		Flags:		make([]*Flag, 0),
+		paramsByShort:	make(map[rune]parameter),
+		paramsByLong:	make(map[string]parameter),
+		params:		make([]parameter, 0),
+		shortLookupMode:	ShortLookupAuto,
+		helpSort:	HelpSortRegistration,
+		maxAfterDash:	-1,
+		suggestDistance:	2,
+		aliasesByShort:	make(map[rune][]string),
+		aliasesByLong:	make(map[string][]string),
+	}
+}
+
+//Parser used by the package level functions, for the program's own
+//command line.
+var CommandLine *Parser = NewParser()
+
+//Options registered with CommandLine, copied from CommandLine.Options by
+//every package level function.  Assigning to it has no effect.
+var Options []*Option = CommandLine.Options
+
+//Flags registered with CommandLine, copied from CommandLine.Flags by every
+//package level function.  Assigning to it has no effect.
+var Flags []*Flag = CommandLine.Flags
+
+//OnRestArg of CommandLine.
+var OnRestArg func(arg string, afterDash bool) bool
+
+//OnWarning of CommandLine.
+var OnWarning func(msg string)
+
+//CommandLine, after taking the package level variables that can be
+//assigned directly.  CommandLine holds the registered flags and options,
+//which may have been added through its own methods, so they are only ever
+//copied out of it.
+func commandLine() *Parser {
+	syncCommandLine()
+	CommandLine.OnRestArg = OnRestArg
+	CommandLine.OnWarning = OnWarning
+	return CommandLine
+}
+
+//Copy the registered flags and options of CommandLine back to the package
+//level variables.
+func syncCommandLine() {
+	Options = CommandLine.Options
+	Flags = CommandLine.Flags
+}
+
+func resetParams() {
+	CommandLine = NewParser()
+	syncCommandLine()
+	OnRestArg = nil
+	OnWarning = nil
+	ExitFunc = os.Exit
+}
+
+//How options and operands may be mixed.
+type ScanMode int
+
+const(
+	//Options may come anywhere, before or after operands, so
+	//mytool a.txt -v sets -v.  Only '--' ends option parsing.
+	ScanPermute ScanMode = iota
+	//The first operand ends option parsing, like getopt with
+	//POSIXLY_CORRECT, so mytool a.txt -v has the operands a.txt and -v.
+	ScanPOSIX
+)
+
+//Choose how options and operands may be mixed.  The default is ScanPermute,
+//as in GNU getopt.  Either way, the Index of each operand in Rest gives its
+//position in argv, so the original order can be recovered.
+func (ps *Parser)SetScanMode(mode ScanMode) {
+	ps.scanMode = mode
+}
+
+//Choose how options and operands may be mixed for CommandLine.
+func SetScanMode(mode ScanMode) {
+	commandLine().SetScanMode(mode)
+}
+
+//Whether arg is an operand rather than an option.  '-' alone is an operand,
+//and empty arguments are neither.
+func isOperand(arg string) bool {
+	return len(arg) == 1 || (len(arg) > 1 && arg[0] != '-' && arg[0] != '+')
+}
+
+//How short options are looked up while parsing.
+type ShortLookup int
+
+const(
+	//Use the array when there are few enough short options
+	ShortLookupAuto ShortLookup = iota
+	//Always look up short options in the map
+	ShortLookupMap
+	//Look up short options below 256, which covers ASCII and Latin-1, in
+	//an array indexed by character, and the others in the map
+	ShortLookupArray
+)
+
+//Largest number of short options for which ShortLookupAuto uses the array.
+const maxArrayShorts = 8
+
+//Choose how short options are looked up.  Parsing the same arguments gives
+//the same result either way; the array avoids hashing for small option sets.
+func (ps *Parser)SetShortLookup(mode ShortLookup) {
+	ps.shortLookupMode = mode
+	ps.shortTable = nil
+}
+
+//Choose how short options of CommandLine are looked up.
+func SetShortLookup(mode ShortLookup) {
+	commandLine().SetShortLookup(mode)
+}
+
+//Build or discard the short option array according to the lookup mode.
+func (ps *Parser)prepareShortTable() {
+	useArray := ps.shortLookupMode == ShortLookupArray ||
+		(ps.shortLookupMode == ShortLookupAuto && len(ps.paramsByShort) <= maxArrayShorts)
+	if !useArray {
+		ps.shortTable = nil
+		return
+	}
+	if ps.shortTable != nil && ps.shortTableSize == len(ps.paramsByShort) {
+		return
+	}
+	ps.shortTable = new([256]parameter)
+	for s, p := range ps.paramsByShort {
+		if s < rune(len(ps.shortTable)) {
+			ps.shortTable[s] = p
+		}
+	}
+	ps.shortTableSize = len(ps.paramsByShort)
+}
+
+func (ps *Parser)lookupShort(s rune) (parameter, bool) {
+	if ps.shortTable != nil && s >= 0 && s < rune(len(ps.shortTable)) {
+		p := ps.shortTable[s]
+		return p, p.opt != nil || p.flag != nil
+	}
+	p, ok := ps.paramsByShort[s]
+	return p, ok
+}
+
+//By default '--' after an option expecting an argument, as in --file --,
+//is taken as the argument.  With wins set, '--' always ends option parsing,
+//so the option is missing its argument and parsing returns an error.
+func (ps *Parser)SetTerminatorAlwaysWins(wins bool) {
+	ps.terminatorAlwaysWins = wins
+}
+
+//Make '--' always end option parsing for CommandLine.
+func SetTerminatorAlwaysWins(wins bool) {
+	commandLine().SetTerminatorAlwaysWins(wins)
+}
+
+//Like GNU getopt_long, make -W name the same as --name, so -W verbose
+//sets --verbose and -Wfile=x sets --file to x.  Usually w is 'W'; it can be
+//another character if 'W' is already used.  0 turns this off, which is the
+//default.  The short option w takes precedence over any registered
+//option with the same letter.
+func (ps *Parser)SetWExtension(w rune) {
+	ps.wExtension = w
+}
+
+//Make -W name the same as --name for CommandLine.
+func SetWExtension(w rune) {
+	commandLine().SetWExtension(w)
+}
+
+//Record the arguments of each parse exactly as given, so a wrapper can
+//forward the invocation unchanged.  See RawArgs.
+func (ps *Parser)SetCaptureRaw(capture bool) {
+	ps.captureRaw = capture
+}
+
+//Record the arguments of each parse of CommandLine.
+func SetCaptureRaw(capture bool) {
+	commandLine().SetCaptureRaw(capture)
+}
+
+//Arguments seen by the last parse, without the program name, in order and
+//unchanged, options included.  Empty unless SetCaptureRaw(true) was called.
+func (ps *Parser)RawArgs() []string {
+	return ps.rawArgs
+}
+
+//Arguments seen by the last parse of CommandLine.
+func RawArgs() []string {
+	return commandLine().RawArgs()
+}
+
+//Accept -o=file as well as -ofile, by dropping one '=' at the start of an
+//argument attached to a short option, in a clump or alone.  -o= gives an
+//empty argument.  A short option at the end of its clump, as in -xo, still
+//takes the next argument.  Off by default, when -o=file gives "=file".
+func (ps *Parser)SetEqualInShort(equal bool) {
+	ps.equalInShort = equal
+}
+
+//Accept -o=file as well as -ofile in CommandLine.
+func SetEqualInShort(equal bool) {
+	commandLine().SetEqualInShort(equal)
+}
+
+//Accept long options with a single dash, like -verbose, as some older tools
+//do.  An argument is still read as a clump of short options when every
+//character is a short option, so -av sets -a and -v even if there is a long
+//option --av.  Only otherwise is it looked up as a long option.
+func (ps *Parser)SetSingleDashLong(single bool) {
+	ps.singleDashLong = single
+}
+
+//Accept long options with a single dash in CommandLine.
+func SetSingleDashLong(single bool) {
+	commandLine().SetSingleDashLong(single)
+}
+
+//Panic when registering a short only option and a long only option that
+//plausibly name the same thing, but disagree on whether they take an
+//argument, like a flag -f and an option --file.  They plausibly name the
+//same thing when the long option starts with the short option's letter.
+func (ps *Parser)SetStrictRegistration(strict bool) {
+	ps.strictRegistration = strict
+}
+
+//Check registrations with CommandLine for mismatched short and long options.
+func SetStrictRegistration(strict bool) {
+	commandLine().SetStrictRegistration(strict)
+}
+
+//A short option -v and a long option --v are separate options.  Registering
+//both for different flags or options is almost always a mistake, so by default
+//it produces a warning.  With unify set, they are treated as the same name and
+//registering the second panics like any other duplicate.
+func (ps *Parser)SetUnifyShortLong(unify bool) {
+	ps.unifyShortLong = unify
+}
+
+//Treat short options and single character long options of CommandLine
+//as the same name.
+func SetUnifyShortLong(unify bool) {
+	commandLine().SetUnifyShortLong(unify)
+}
+
+//Recover panics in Action, OnTrue, OnFalse, and their Context variants and
+//return them from parsing
+//as errors, so a misbehaving callback cannot crash a program embedding the
+//parser.
+func (ps *Parser)SetRecoverCallbacks(recovering bool) {
+	ps.recoverCallbacks = recovering
+}
+
+//Recover panics in callbacks of CommandLine.
+func SetRecoverCallbacks(recovering bool) {
+	commandLine().SetRecoverCallbacks(recovering)
+}
+
+//Hold Action, OnTrue, OnFalse, and their Context variants until every
+//argument has parsed and the checks after parsing pass, then call them in
+//the order their flags and options were passed.  A mistake late on the
+//command line then leaves the program untouched by the callbacks of
+//earlier arguments.
+func (ps *Parser)SetDeferCallbacks(deferring bool) {
+	ps.deferCallbacks = deferring
+}
+
+//Defer callbacks of CommandLine until parsing succeeds.
+func SetDeferCallbacks(deferring bool) {
+	commandLine().SetDeferCallbacks(deferring)
+}
+
+//Report a diagnostic through OnWarning or to standard error.
+func (ps *Parser)warn(format string, a ...any) {
+	msg := fmt.Sprintf(format, a...)
+	if ps.OnWarning != nil {
+		ps.OnWarning(msg)
+	} else {
+		fmt.Fprintln(os.Stderr, msg)
+	}
+}
+
+//Clear the values of every flag and option, keeping them registered, so a
+//program that parses many commands, like a REPL, starts each one fresh.
+//Typed values go back to their defaults, and bound variables to what they
+//held when they were bound.
+func (ps *Parser)ResetValues() {
+	for _, opt := range ps.Options {
+		opt.Clear()
+	}
+	for _, flag := range ps.Flags {
+		flag.Clear()
+	}
+	for _, reset := range ps.unbound {
+		reset()
+	}
+}
+
+//Clear the values of every flag and option of CommandLine.
+func ResetValues() {
+	commandLine().ResetValues()
+}
+
+//Give the flag or option named name, a long option or a short option, value
+//as though --name=value were parsed, for tests and config loaders that
+//should behave exactly like the command line.  Deprecation warnings,
+//Validate, conversion of typed options, counts, and callbacks all apply,
+//and bound variables are updated.  Constraints between options are not
+//checked.
+func (ps *Parser)Set(name, value string) error {
+	p, ok := ps.lookupName(name)
+	if !ok {
+		return ps.unrecognizedLong(strings.TrimLeft(name, "-"))
+	}
+	//Not from argv, and not part of a parse whose callbacks are waiting or
+	//whose deprecation warnings were given
+	ps.argIndex = 0
+	ps.deferred = nil
+	ps.warnedDeprecated = nil
+	ps.origin = Source{ Kind: SourceSet }
+	var err error
+	if p.opt != nil {
+		err = ps.addOptArg(p.opt, value)
+	} else {
+		err = ps.takeString(p.flag, value)
+	}
+	if err == nil {
+		err = ps.runDeferred()
+	}
+	ps.populateBound()
+	return err
+}
+
+//Set the flag or option of CommandLine named name.
+func Set(name, value string) error {
+	return commandLine().Set(name, value)
+}
+
+//Context passed to callbacks:  the one given to ParseContext, or
+//context.Background().
+func (ps *Parser)context() context.Context {
+	if ps.ctx != nil {
+		return ps.ctx
+	}
+	return context.Background()
+}
+
+//Invoke callback f of option o, named kind in errors, or queue it for
+//after parsing if callbacks are deferred.
+func (ps *Parser)runCallback(kind string, o *option, f func() error) error {
+	if ps.deferCallbacks {
+		ps.deferred = append(ps.deferred, func() error {
+			return ps.callCallback(kind, o, f)
+		})
+		return nil
+	}
+	return ps.callCallback(kind, o, f)
+}
+
+//Call the callbacks deferred in this parse, stopping at the first error.
+func (ps *Parser)runDeferred() error {
+	deferred := ps.deferred
+	ps.deferred = nil
+	for _, f := range deferred {
+		if err := f(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Call callback f of option o, named kind in errors.  Nothing is called
+//once the context is done, and its error is returned instead.
+func (ps *Parser)callCallback(kind string, o *option, f func() error) (err error) {
+	if err := ps.context().Err(); err != nil {
+		return err
+	}
+	if ps.recoverCallbacks {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf(errCallbackPanic, kind, o.display(), r)
+			}
+		}()
+	}
+	if err := f(); err != nil {
+		return fmt.Errorf(errCallback, kind, o.display(), err)
+	}
+	return nil
+}
+
+//Warn that o is deprecated, the first time it is used in a parse.
+func (ps *Parser)warnIfDeprecated(o *option) {
+	if !o.deprecated || ps.warnedDeprecated[o] {
+		return
+	}
+	if ps.warnedDeprecated == nil {
+		ps.warnedDeprecated = make(map[*option]bool)
+	}
+	ps.warnedDeprecated[o] = true
+	if o.deprecation == "" {
+		ps.warn(warnDeprecated, o.display())
+	} else {
+		ps.warn(warnDeprecatedMsg, o.display(), o.deprecation)
+	}
+}
+
+//Assign value to flag, update count, and invoke event if applicable.
+func (ps *Parser)takeValue(f *Flag, value bool) error {
+	ps.warnIfDeprecated(&f.option)
+	f.source = ps.valueSource()
+	if value {
+		f.Count++
+	} else {
+		f.Count--
+	}
+	f.Passed = value
+	kind, callback, withContext := "OnTrue", f.OnTrue, f.OnTrueContext
+	if !value {
+		kind, callback, withContext = "OnFalse", f.OnFalse, f.OnFalseContext
+	}
+	if callback != nil {
+		err := ps.runCallback(kind, &f.option, func() error {
+			callback()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if withContext != nil {
+		return ps.runCallback(kind, &f.option, func() error {
+			return withContext(ps.context())
+		})
+	}
+	return nil
+}
+
+//Assign the value of --flag=value.  A number sets Count directly, so
+//--debug=3 is the same as -ddd; Passed is then whether it is positive, and
+//neither OnTrue nor OnFalse is called.  Anything else is read as a boolean.
+func (ps *Parser)takeString(f *Flag, value string) error {
+	ps.warnIfDeprecated(&f.option)
+	f.source = ps.valueSource()
+	if n, err := strconv.Atoi(value); err == nil {
+		f.Count = n
+		f.Passed = n > 0
+		return nil
+	}
+	v, err := parseFlagOpt(f.name(), value)
+	if err != nil {
+		return err
+	}
+	return ps.takeValue(f, v)
+}
+
+//Add option argument to the optarg vector of o and invoke
+//event if applicable.
+func (ps *Parser)addOptArg(o *Option, arg string) error {
+	ps.warnIfDeprecated(&o.option)
+	o.source = ps.valueSource()
+	index := ps.argIndex
+	if o.restOfLine && ps.lineNext != nil {
+		words := []string{ arg }
+		for word, ok := ps.lineNext(); ok; word, ok = ps.lineNext() {
+			ps.readArg(word)
+			words = append(words, word)
+		}
+		arg = strings.Join(words, " ")
+	}
+	if o.pattern != nil && !o.pattern.MatchString(arg) {
+		return fmt.Errorf(errNoMatch, o.display(), o.masked(arg))
+	}
+	if o.Validate != nil {
+		if err := o.Validate(arg); err != nil {
+			return &ErrValidation{
+				Option:	o.display(),
+				Value:	o.masked(arg),
+				Index:	index,
+				Err:	err,
+				reason:	o.maskedError(err, arg),
+			}
+		}
+	}
+	if o.convert != nil {
+		if err := o.convert(arg); err != nil {
+			return err
+		}
+	}
+	if o.splitOn != "" {
+		fields, err := o.split(arg)
+		if err != nil {
+			return err
+		}
+		o.OptArgs = append(o.OptArgs, fields...)
+	} else {
+		o.OptArgs = append(o.OptArgs, arg)
+	}
+	o.OptArg = arg
+	o.Passed = true
+	if o.consumesRemainder {
+		ps.remainderOpt = o
+	}
+	if o.Action != nil {
+		err := ps.runCallback("action", &o.option, func() error {
+			o.Action(arg)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if o.ActionContext != nil {
+		return ps.runCallback("action", &o.option, func() error {
+			return o.ActionContext(ps.context(), arg)
+		})
+	}
+	return nil
+}
+
+//Pass argument to emit to be added to Rest array.  If OnRestArg is not nil, we invoke it
+//on the argument, and we pass it on only if that function returns
+//true.  This is to support cases where the program interprets some sort
+//of command language or similar.
+func (ps *Parser)addRest(emit func(Rest), arg string, dash bool) {
+	if ps.stopAtOperand {
+		if ps.heldBack == nil {
+			ps.heldBack = []string{ arg }
+			ps.heldIndex = ps.argIndex
+		}
+		return
+	}
+	if ps.OnRestArg != nil {
+		if ps.OnRestArg(arg, dash) {
+			emit(Rest{
+				Argument:	arg,
+				AfterDashes:		dash,
+				Index:		ps.argIndex,
+			})
+		}
+	} else {
+		emit(Rest{
+			Argument:	arg,
+			AfterDashes:		dash,
+			Index:		ps.argIndex,
+		})
+	}
+}
+
+//Ensure flags/options can be passed at all
+func checkName(s rune, l string) {
+	if s == 0 && l == "" {
+		panic("Option must have a short or long name")
+	}
+}
+
+//Ensure duplicate flags/options cannot be created
+func (ps *Parser)checkShort(s rune) {
+	if _, ok := ps.paramsByShort[s]; ok {
+		panic("Adding another command line option with same short option")
+	}
+	if _, ok := ps.aliasesByShort[s]; ok {
+		panic("Adding another command line option with same short option")
+	}
+	if _, ok := ps.paramsByLong[string(s)]; ok {
+		ps.sameName(s)
+	}
+}
+
+func (ps *Parser)checkLong(l string) {
+	if _, ok := ps.paramsByLong[l]; ok {
+		panic("Adding another command line option with same long option")
+	}
+	if _, ok := ps.aliasesByLong[l]; ok {
+		panic("Adding another command line option with same long option")
+	}
+	if utf8.RuneCountInString(l) == 1 {
+		s, _ := utf8.DecodeRuneInString(l)
+		if _, ok := ps.paramsByShort[s]; ok {
+			ps.sameName(s)
+		}
+	}
+}
+
+//Whether the short option s and long option l plausibly name the same
+//thing:  l is s itself, like -f and --f, or starts with s, like -f and
+//--file.
+func plausiblySame(s rune, l string) bool {
+	return strings.HasPrefix(l, string(s))
+}
+
+//With strict registration, a short only option and a long only option
+//that plausibly name the same thing must agree on whether they take an
+//argument.  Parameters with both forms are consistent by construction, so
+//are not compared.
+func (ps *Parser)checkArity(s rune, l string, takesArg bool) {
+	if !ps.strictRegistration {
+		return
+	}
+	for _, p := range ps.params {
+		o := p.base()
+		if err := arityMismatch(s, l, takesArg, o.ShortOpt, o.LongOpt, o.takesArg); err != nil {
+			panic(err.Error())
+		}
+	}
+}
+
+//Error if the parameter with short option s and long option l disagrees
+//with the one with short option os and long option ol on whether they take
+//an argument, when one is short only and the other long only.
+func arityMismatch(s rune, l string, takesArg bool, os rune, ol string, oTakesArg bool) error {
+	if (s != 0 && l != "") || takesArg == oTakesArg {
+		return nil
+	}
+	if l == "" && os == 0 && plausiblySame(s, ol) {
+		return fmt.Errorf(errArityMismatch, s, ol)
+	}
+	if s == 0 && ol == "" && plausiblySame(os, l) {
+		return fmt.Errorf(errArityMismatch, os, l)
+	}
+	return nil
+}
+
+//Short option s and long option of the same single character belong to
+//different options.
+func (ps *Parser)sameName(s rune) {
+	if ps.unifyShortLong {
+		panic("Adding command line option with same name as another short or long option")
+	}
+	ps.warn(warnSameShortLong, s, s)
+}
+
+func (ps *Parser)NewFlag(s rune, l string, h string) *Flag {
+	checkName(s, l)
+	ps.checkShort(s)
+	ps.checkLong(l)
+	ps.checkArity(s, l, false)
+
+	flag := Flag{
+		option:	option{
+			ShortOpt:	s,
+			LongOpt:	l,
+			Help:		h,
+			takesArg:	false,
+		},
+	}
+
+	ps.Flags = append(ps.Flags, &flag)
+	p := parameter{flag: &flag}
+	ps.params = append(ps.params, p)
+	ps.paramsByShort[s] = p
+	ps.paramsByLong[l] = p
+	return &flag
+}
+
+func NewFlag(s rune, l string, h string) *Flag {
+	defer syncCommandLine()
+	return commandLine().NewFlag(s, l, h)
+}
+
+func (ps *Parser)NewFlagShort(s rune, h string) *Flag {
+	checkName(s, "")
+	ps.checkShort(s)
+	ps.checkArity(s, "", false)
+	flag := Flag{
+		option:	option{
+			ShortOpt:	s,
+			Help:		h,
+			takesArg:	false,
+		},
+	}
+
+	ps.Flags = append(ps.Flags, &flag)
+	p := parameter{flag: &flag}
+	ps.params = append(ps.params, p)
+	ps.paramsByShort[s] = p
+	return &flag
+}
+
+func NewFlagShort(s rune, h string) *Flag {
+	defer syncCommandLine()
+	return commandLine().NewFlagShort(s, h)
+}
+
+func (ps *Parser)NewFlagLong(l string, h string) *Flag {
+	checkName(0, l)
+	ps.checkLong(l)
+	ps.checkArity(0, l, false)
+	flag := Flag{
+		option:	option{
+			LongOpt:	l,
+			Help:		h,
+			takesArg:	false,
+		},
+	}
+
+	ps.Flags = append(ps.Flags, &flag)
+	p := parameter{flag: &flag}
+	ps.params = append(ps.params, p)
+	ps.paramsByLong[l] = p
+	return &flag
+}
+
+func NewFlagLong(l string, h string) *Flag {
+	defer syncCommandLine()
+	return commandLine().NewFlagLong(l, h)
+}
+
+func (ps *Parser)NewOption(s rune, l string, h string) *Option {
+	checkName(s, l)
+	ps.checkShort(s)
+	ps.checkLong(l)
+	ps.checkArity(s, l, true)
+	opt := Option{
+		option: option{
+			LongOpt:	l,
+			ShortOpt:	s,
+			Help:		h,
+			takesArg:	true,
+		},
+	}
+
+	ps.Options = append(ps.Options, &opt)
+	p := parameter{opt: &opt}
+	ps.params = append(ps.params, p)
+	ps.paramsByShort[s] = p
+	ps.paramsByLong[l] = p
+	return &opt
+}
+
+func NewOption(s rune, l string, h string) *Option {
+	defer syncCommandLine()
+	return commandLine().NewOption(s, l, h)
+}
+
+func (ps *Parser)NewOptionShort(s rune, h string) *Option {
+	checkName(s, "")
+	ps.checkShort(s)
+	ps.checkArity(s, "", true)
+	opt := Option{
+		option: option{
+			ShortOpt:	s,
+			Help:		h,
+			takesArg:	true,
+		},
+	}
+
+	ps.Options = append(ps.Options, &opt)
+	p := parameter{opt: &opt}
+	ps.params = append(ps.params, p)
+	ps.paramsByShort[s] = p
+	return &opt
+}
+
+func NewOptionShort(s rune, h string) *Option {
+	defer syncCommandLine()
+	return commandLine().NewOptionShort(s, h)
+}
+
+func (ps *Parser)NewOptionLong(l string, h string) *Option {
+	checkName(0, l)
+	ps.checkLong(l)
+	ps.checkArity(0, l, true)
+	opt := Option{
+		option: option{
+			LongOpt:	l,
+			Help:		h,
+			takesArg:	true,
+		},
+	}
+
+	ps.Options = append(ps.Options, &opt)
+	p := parameter{opt: &opt}
+	ps.params = append(ps.params, p)
+	ps.paramsByLong[l] = p
+	return &opt
+}
+
+func NewOptionLong(l string, h string) *Option {
+	defer syncCommandLine()
+	return commandLine().NewOptionLong(l, h)
+}
+
+//Everything learned from parsing an argument vector besides the values of
+//the flags and options.
+type ParseResult struct {
+	//Operands, in order
+	Rest	[]Rest
+	//Index in argv of '--', or -1 if it was not passed
+	DashIndex	int
+	//Number of flags and options passed, counting repeats
+	OptionsParsed	int
+	//Unrecognized options as they were written, when parsing is lenient
+	Unknown	[]string
+}
+
+//Parse argv, where argv[0] is the program name, as in os.Args.  An empty
+//argv has no options or operands.
+func (ps *Parser)Parse(argv []string) (*ParseResult, error) {
+	rest, err := ps.parseArgv(argv)
+	if err != nil {
+		return nil, err
+	}
+	return &ParseResult{
+		Rest:		rest,
+		DashIndex:	ps.dashIndex,
+		OptionsParsed:	ps.optionsParsed,
+		Unknown:	ps.unknown,
+	}, nil
+}
+
+//Parse argv with CommandLine, returning everything learned.
+func Parse(argv []string) (*ParseResult, error) {
+	return commandLine().Parse(argv)
+}
+
+//Parse argv, returning only the operands.
+func (ps *Parser)parseArgv(argv []string) ([]Rest, error) {
+	rest, err := ps.parseArgvUnhandled(argv)
+	return rest, ps.handleError(err)
+}
+
+//parseArgv without the error handling mode applied.
+func (ps *Parser)parseArgvUnhandled(argv []string) ([]Rest, error) {
+	if len(argv) > 0 && argv[0] != "" {
+		ps.argvName = filepath.Base(argv[0])
+	}
+	//Every argument may be an operand, so reserve room for all of them
+	//up front rather than growing the slice.
+	rest := make([]Rest, 0, len(argv))
+	i := 1
+	next := func() (string, bool) {
+		if i >= len(argv) {
+			return "", false
+		}
+		i++
+		return argv[i-1], true
+	}
+	err := ps.parseFunc(next, func(r Rest) {
+		rest = append(rest, r)
+	})
+	return rest, err
+}
+
+//Parse argv with CommandLine, where argv[0] is the program name, as in
+//os.Args.
+func ArgParse(argv []string) ([]Rest, error) {
+	return commandLine().parseArgv(argv)
+}
+
+//Parse argv like ArgParse, passing ctx to ActionContext, OnTrueContext,
+//and OnFalseContext.  Once ctx is done, no more callbacks are called and
+//parsing fails with ctx.Err(), even if no callback was due.
+func (ps *Parser)ParseContext(ctx context.Context, argv []string) ([]Rest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, ps.handleError(err)
+	}
+	ps.ctx = ctx
+	defer func() {
+		ps.ctx = nil
+	}()
+	rest, err := ps.parseArgvUnhandled(argv)
+	if err == nil {
+		err = ctx.Err()
+	}
+	return rest, ps.handleError(err)
+}
+
+//Parse argv with CommandLine, passing ctx to callbacks.
+func ArgParseContext(ctx context.Context, argv []string) ([]Rest, error) {
+	return commandLine().ParseContext(ctx, argv)
+}
+
+//Parse arguments produced by next, which returns false when there are no
+//more.  Unlike Parse, next should not produce the program name.  Operands
+//are passed to emit as soon as they are recognized instead of being collected,
+//so very long argument lists need not be held in memory.
+func (ps *Parser)ParseFunc(next func() (string, bool), emit func(Rest)) error {
+	return ps.handleError(ps.parseFunc(next, emit))
+}
+
+//Parse arguments produced by next with CommandLine.
+func ArgParseFunc(next func() (string, bool), emit func(Rest)) error {
+	return commandLine().ParseFunc(next, emit)
+}
+
+//Count arg, just read from the command line, for Rest.Index, and record it
+//for RawArgs if asked to.
+func (ps *Parser)readArg(arg string) {
+	ps.argIndex++
+	if ps.captureRaw {
+		ps.rawArgs = append(ps.rawArgs, arg)
+	}
+}
+
+//ParseFunc without the error handling mode applied.
+func (ps *Parser)parseFunc(next func() (string, bool), emit func(Rest)) error {
+	ps.argIndex = 0
+	ps.dashIndex = -1
+	ps.optionsParsed = 0
+	ps.unknown = nil
+	ps.errs = nil
+	ps.warnedDeprecated = nil
+	ps.deferred = nil
+	ps.origin = Source{ Kind: SourceCommandLine }
+	if ps.captureRaw {
+		ps.rawArgs = make([]string, 0)
+	}
+	source := next
+	next = func() (string, bool) {
+		arg, ok := source()
+		if ok {
+			ps.readArg(arg)
+		}
+		return arg, ok
+	}
+	ps.afterDashCount = 0
+	counted := func(r Rest) {
+		if r.AfterDashes {
+			ps.afterDashCount++
+		}
+		emit(r)
+	}
+	if err := ps.parseArgs(next, counted); err != nil {
+		return err
+	}
+	if err := ps.handleAutoFlags(); err != nil {
+		return err
+	}
+	if err := ps.collect(ps.finishParse()); err != nil {
+		return err
+	}
+	if len(ps.errs) > 0 {
+		return &ErrMultiple{ Errors: ps.errs }
+	}
+	return ps.runDeferred()
+}
+
+//Parse a command given as a single line, split into words at white space.
+//Unlike Parse, there is no program name.
+func (ps *Parser)ParseString(line string) ([]Rest, error) {
+	return ps.parseWords(strings.Fields(line))
+}
+
+//Parse a command given as a single line with CommandLine.
+func ParseString(line string) ([]Rest, error) {
+	return commandLine().ParseString(line)
+}
+
+//Parse the words of a line, without a program name.
+func (ps *Parser)parseWords(words []string) ([]Rest, error) {
+	rest, err := ps.parseWordList(words)
+	return rest, ps.handleError(err)
+}
+
+//parseWords without the error handling mode applied.
+func (ps *Parser)parseWordList(words []string) ([]Rest, error) {
+	rest := make([]Rest, 0, len(words))
+	i := 0
+	ps.lineNext = func() (string, bool) {
+		if i >= len(words) {
+			return "", false
+		}
+		i++
+		return words[i-1], true
+	}
+	defer func() {
+		ps.lineNext = nil
+	}()
+	err := ps.parseFunc(ps.lineNext, func(r Rest) {
+		rest = append(rest, r)
+	})
+	return rest, err
+}
+
+//Parse options up to the first operand, for programs that hand everything
+//from a subcommand on to something else.  args includes the program name,
+//like Parse.  Returns the index in args of the first operand and the
+//unparsed arguments starting with it.  If the operand came from an alias or
+//response file, the index is that of the argument naming it, and the
+//unparsed arguments start with the operand and the rest of the expansion,
+//followed by the arguments after it.  If '--' comes first, the remaining
+//arguments start after it.  Unrecognized options before the first operand
+//are still errors.
+func (ps *Parser)ParseGlobals(args []string) (int, []string, error) {
+	i := 1
+	next := func() (string, bool) {
+		if i >= len(args) {
+			return "", false
+		}
+		i++
+		return args[i-1], true
+	}
+	ps.stopAtOperand = true
+	ps.heldBack = nil
+	defer func() {
+		ps.stopAtOperand = false
+		ps.heldBack = nil
+	}()
+	err := ps.ParseFunc(next, func(Rest) {})
+	index, rest := i, args[i:]
+	if ps.heldBack != nil {
+		index = ps.heldIndex
+		rest = append(ps.heldBack, args[i:]...)
+	}
+	if err != nil {
+		return index, nil, err
+	}
+	return index, rest, nil
+}
+
+//Parse options up to the first operand with CommandLine.
+func ParseGlobals(args []string) (int, []string, error) {
+	return commandLine().ParseGlobals(args)
+}
+
+//Work done once every argument has been seen:  fall back to the environment
+//for options that were not passed, then to the config file and their
+//defaults, settle linked counts, fill in bound struct fields, then check
+//constraints between options.
+func (ps *Parser)finishParse() error {
+	//Values from here on are not from argv
+	ps.argIndex = 0
+	if err := ps.applyEnv(); err != nil {
+		return err
+	}
+	if err := ps.applyConfig(); err != nil {
+		return err
+	}
+	if err := ps.applyDefaults(); err != nil {
+		return err
+	}
+	if err := ps.resolveCountLinks(); err != nil {
+		return err
+	}
+	ps.populateBound()
+	return ps.checkConstraints()
+}
+
+//Give options that were not passed their default.  OptArgs is left empty
+//and Passed false, so a default does not satisfy a required option.
+func (ps *Parser)applyDefaults() error {
+	for _, opt := range ps.Options {
+		if opt.Passed || opt.Default == "" {
+			continue
+		}
+		if opt.convert != nil {
+			if err := opt.convert(opt.Default); err != nil {
+				return err
+			}
+		}
+		opt.OptArg = opt.Default
+		opt.source = Source{ Kind: SourceDefault }
+	}
+	return nil
+}
+
+//Handle a long option given as name or name=value, where spec is arg
+//without its leading dashes.  Returns the option if it is still waiting
+//for arguments, and how many.
+func (ps *Parser)parseLong(arg, spec string) (*Option, int, error) {
+	indexOfEquals := strings.IndexByte(spec, '=')
+	if indexOfEquals < 0 {
+		if p, err := ps.lookupLong(spec); err == nil {
+			ps.optionsParsed++
+			if p.takesArgument() {
+				needed, err := ps.bareOption(p.opt)
+				return p.opt, needed, err
+			} else {
+				if err := ps.takeValue(p.flag, true); err != nil {
+					return nil, 0, err
+				}
+			}
+		} else if flag := ps.negatedFlag(spec); flag != nil {
+			ps.optionsParsed++
+			if err := ps.takeValue(flag, false); err != nil {
+				return nil, 0, err
+			}
+		} else {
+			return nil, 0, ps.skipUnknown(arg, err)
+		}
+	} else if indexOfEquals == 0 {
+		//Nothing between dashes and '='
+		return nil, 0, fmt.Errorf(errEmptyLongOption, arg)
+	} else {
+		long := spec[:indexOfEquals]
+		optarg := spec[indexOfEquals+1:]
+		if p, err := ps.lookupLong(long); err == nil {
+			ps.optionsParsed++
+			if p.takesArgument() {
+				if err := ps.addOptArg(p.opt, optarg); err != nil {
+					return nil, 0, err
+				}
+				return p.opt, p.opt.arity() - 1, nil
+			} else {
+				if err := ps.takeString(p.flag, optarg); err != nil {
+					return nil, 0, err
+				}
+			}
+		} else {
+			return nil, 0, ps.skipUnknown(arg, err)
+		}
+	}
+	return nil, 0, nil
+}
+
+//Number of arguments opt still needs after being passed with none attached.
+//An option with an optional argument takes its implicit value instead.
+func (ps *Parser)bareOption(opt *Option) (int, error) {
+	if !opt.optionalArg {
+		return opt.arity(), nil
+	}
+	return 0, ps.addOptArg(opt, opt.implicitArg)
+}
+
+//Whether every character of arg after the dash is a short option, up to
+//one that takes the rest of the clump as its argument.
+func (ps *Parser)isClump(arg string) bool {
+	for _, s := range arg[1:] {
+		if ps.wExtension != 0 && s == ps.wExtension {
+			return true
+		}
+		p, ok := ps.lookupShort(s)
+		if !ok {
+			return false
+		}
+		if p.takesArgument() {
+			return true
+		}
+	}
+	return true
+}
+
+//Whether spec, as name or name=value, names a registered long option,
+//possibly ambiguously.
+func (ps *Parser)isLong(spec string) bool {
+	if i := strings.IndexByte(spec, '='); i >= 0 {
+		spec = spec[:i]
+	}
+	_, err := ps.lookupLong(spec)
+	var ambiguous *ErrAmbiguousOption
+	return err == nil || errors.As(err, &ambiguous)
+}
+
+//Accept --no-verbose as well as --verbose=false to negate the flag
+//--verbose, and show it as --[no-]verbose in help.  A long option that is
+//registered with the no- prefix is used as is.  Off by default.
+func (ps *Parser)SetNegateWithNo(negate bool) {
+	ps.negateWithNo = negate
+}
+
+//Accept --no-verbose to negate flags of CommandLine.
+func SetNegateWithNo(negate bool) {
+	commandLine().SetNegateWithNo(negate)
+}
+
+//Flag negated by the long option spec, like verbose for no-verbose, or nil.
+func (ps *Parser)negatedFlag(spec string) *Flag {
+	if !ps.negateWithNo || !strings.HasPrefix(spec, "no-") {
+		return nil
+	}
+	p, err := ps.lookupLong(spec[3:])
+	if err != nil {
+		return nil
+	}
+	return p.flag
+}
+
+//Accept unambiguous prefixes of long options, like GNU getopt_long, so
+//--verb is --verbose unless another long option also starts with verb.  An
+//exact match always wins.  Off by default.
+func (ps *Parser)SetAbbreviations(abbreviations bool) {
+	ps.abbreviations = abbreviations
+}
+
+//Accept unambiguous prefixes of long options of CommandLine.
+func SetAbbreviations(abbreviations bool) {
+	commandLine().SetAbbreviations(abbreviations)
+}
+
+//Collect unrecognized options instead of failing, for wrappers that pass
+//them on to another program.  Each is kept as it was written, like --foo=bar
+//or -x, or -x alone from a clump like -vxq, and is assumed to take no
+//argument.  ParseResult.Unknown lists them.  Ambiguous abbreviations are
+//still errors.  Off by default.
+func (ps *Parser)SetLenient(lenient bool) {
+	ps.lenient = lenient
+}
+
+//Collect unrecognized options of CommandLine instead of failing.
+func SetLenient(lenient bool) {
+	commandLine().SetLenient(lenient)
+}
+
+//Keep arg as an unknown option and return nil if parsing is lenient and err
+//is about an unrecognized option, otherwise return err.
+func (ps *Parser)skipUnknown(arg string, err error) error {
+	var unknown *ErrUnknownOption
+	if !ps.lenient || !errors.As(err, &unknown) {
+		return err
+	}
+	ps.unknown = append(ps.unknown, arg)
+	return nil
+}
+
+//Keep parsing after an error and return every error found, wrapped in an
+//ErrMultiple, so a user can fix all of their mistakes in one go.  Checks
+//made after parsing, like required options, stop at their first error as
+//usual.  Off by default.
+func (ps *Parser)SetCollectErrors(collect bool) {
+	ps.collectErrors = collect
+}
+
+//Keep parsing after an error with CommandLine.
+func SetCollectErrors(collect bool) {
+	commandLine().SetCollectErrors(collect)
+}
+
+//Keep err and return nil if collecting errors, otherwise return err.
+func (ps *Parser)collect(err error) error {
+	if err == nil || !ps.collectErrors {
+		return err
+	}
+	ps.errs = append(ps.errs, err)
+	return nil
+}
+
+//The parameter with long option l, or with l as an unambiguous prefix
+//when abbreviations are accepted.
+func (ps *Parser)lookupLong(l string) (parameter, error) {
+	if p, ok := ps.paramsByLong[l]; ok {
+		return p, nil
+	}
+	if ps.abbreviations && l != "" {
+		var match parameter
+		candidates := make([]string, 0)
+		for _, p := range ps.params {
+			//Each parameter counts once, whichever of its long
+			//options match
+			for _, long := range p.base().longs() {
+				if strings.HasPrefix(long, l) {
+					match = p
+					candidates = append(candidates, "--" + long)
+					break
+				}
+			}
+		}
+		if len(candidates) == 1 {
+			return match, nil
+		} else if len(candidates) > 1 {
+			return parameter{}, &ErrAmbiguousOption{
+				Name:		l,
+				Candidates:	candidates,
+			}
+		}
+	}
+	return parameter{}, ps.unrecognizedLong(l)
+}
+
+//The parsing state machine behind Parse and ParseFunc.
+func (ps *Parser)parseArgs(next func() (string, bool), emit func(Rest)) error {
+	//Number of arguments waiting_opt is still waiting for
+	optargs_needed := 0
+	var waiting_opt *Option
+	//Whether the previous argument was -W, so this one is a long option
+	expect_long := false
+	//Expansion of an alias or response file, parsed before the rest of the
+	//arguments
+	queued := make([]string, 0)
+	source := next
+	next = func() (string, bool) {
+		if ps.heldBack != nil {
+			//ParseGlobals reached an operand, so the rest of any
+			//expansion is left unparsed too
+			ps.heldBack = append(ps.heldBack, queued...)
+			queued = queued[:0]
+			return "", false
+		}
+		if len(queued) > 0 {
+			arg := queued[0]
+			queued = queued[1:]
+			return arg, true
+		}
+		return source()
+	}
+	ps.remainderOpt = nil
+	ps.prepareShortTable()
+	for {
+		arg, ok := next()
+		if !ok {
+			break
+		}
+		if ps.remainderOpt != nil {
+			for ; ok; arg, ok = next() {
+				ps.remainderOpt.OptArgs = append(ps.remainderOpt.OptArgs, arg)
+			}
+			ps.remainderOpt = nil
+			break
+		}
+		if expect_long {
+			waiting, needed, err := ps.parseLong(arg, arg)
+			if err := ps.collect(err); err != nil {
+				return err
+			}
+			if needed > 0 {
+				waiting_opt = waiting
+				optargs_needed = needed
+			}
+			expect_long = false
+			continue
+		}
+		if optargs_needed > 0 {
+			if arg == "--" && ps.terminatorAlwaysWins {
+				if err := ps.collect(missingArgument(waiting_opt.display())); err != nil {
+					return err
+				}
+				//Carry on with '--' as the terminator
+				optargs_needed = 0
+			} else {
+				if err := ps.collect(ps.addOptArg(waiting_opt, arg)); err != nil {
+					return err
+				}
+				optargs_needed--
+				continue
+			}
+		}
+
+		if ps.isResponseFile(arg) {
+			expansion, err := ps.expandResponseFile(arg[1:], 0)
+			if err := ps.collect(err); err != nil {
+				return err
+			}
+			queued = append(expansion, queued...)
+			continue
+		}
+
+		if ps.scanMode == ScanPOSIX && isOperand(arg) {
+			for ; ok; arg, ok = next() {
+				ps.addRest(emit, arg, false)
+			}
+			break
+		}
+
+		if _, ok := ps.lookupAlias(arg); ok {
+			expansion, err := ps.expandAliases([]string{ arg }, 0)
+			if err := ps.collect(err); err != nil {
+				return err
+			}
+			queued = append(expansion, queued...)
+			continue
+		}
+
+		l := len(arg)
+		switch l {
+		case 0:		//Ignore empty arguments
+		case 1: 	//Either '-' or an argument
+			//rest = append(rest, arg)
+			ps.addRest(emit, arg, false)
+		case 2: 	//Either -a, +b, --, or rest
+			s, _ := utf8.DecodeRuneInString(arg[1:])
+			if arg == "--" {
+				ps.dashIndex = ps.argIndex
+				for arg, ok := next(); ok; arg, ok = next() {
+					//rest = append(rest, arg)
+					ps.addRest(emit, arg, true)
+				}
+				return nil
+			} else if arg[0] == '-' && ps.wExtension != 0 && s == ps.wExtension {
+				expect_long = true
+			} else if arg[0] == '-' {
+				if p, ok := ps.lookupShort(s); ok {
+					ps.optionsParsed++
+					if p.takesArgument() {
+						needed, err := ps.bareOption(p.opt)
+						if err := ps.collect(err); err != nil {
+							return err
+						}
+						waiting_opt = p.opt
+						optargs_needed = needed
+					} else {
+						if err := ps.collect(ps.takeValue(p.flag, true)); err != nil {
+							return err
+						}
+					}
+				} else if err := ps.collect(ps.skipUnknown(arg, unrecognizedShort(s))); err != nil {
+					return err
+				}
+			} else if arg[0] == '+' {
+				if p, ok := ps.lookupShort(s); ok {
+					ps.optionsParsed++
+					if p.takesArgument() {
+						if err := ps.collect(negatedOption(s)); err != nil {
+							return err
+						}
+					} else {
+						if err := ps.collect(ps.takeValue(p.flag, false)); err != nil {
+							return err
+						}
+					}
+				} else if err := ps.collect(ps.skipUnknown(arg, unrecognizedShort(s))); err != nil {
+					return err
+				}
+			} else {
+				//rest = append(rest, arg)
+				ps.addRest(emit, arg, false)
+
+			}
+		default:	//Either --blah or --foo=bar or -abc or +abc or rest
+			if arg[0] == '-' {
+				if arg[1] == '-' {
+					//Long option
+					waiting, needed, err := ps.parseLong(arg, arg[2:])
+					if err := ps.collect(err); err != nil {
+						return err
+					}
+					if needed > 0 {
+						waiting_opt = waiting
+						optargs_needed = needed
+					}
+				} else if ps.singleDashLong && !ps.isClump(arg) && ps.isLong(arg[1:]) {
+					//Long option with a single dash
+					waiting, needed, err := ps.parseLong(arg, arg[1:])
+					if err := ps.collect(err); err != nil {
+						return err
+					}
+					if needed > 0 {
+						waiting_opt = waiting
+						optargs_needed = needed
+					}
+				} else {
+					//clump
+					for j, width := 1, 0; j < len(arg); j += width {
+						s, n := utf8.DecodeRuneInString(arg[j:])
+						width = n
+						if ps.wExtension != 0 && s == ps.wExtension {
+							//The rest of the clump, or the next argument,
+							//is a long option
+							if j + width < len(arg) {
+								waiting, needed, err := ps.parseLong(arg, arg[j+width:])
+								if err := ps.collect(err); err != nil {
+									return err
+								}
+								if needed > 0 {
+									waiting_opt = waiting
+									optargs_needed = needed
+								}
+							} else {
+								expect_long = true
+							}
+							break
+						}
+						if p, ok := ps.lookupShort(s); ok {
+							ps.optionsParsed++
+							if p.takesArgument() {
+								if j + width < len(arg) {
+									//The rest of the clump is the argument to last
+									//recognized short option
+									optarg := arg[j+width:]
+									if ps.equalInShort && optarg[0] == '=' {
+										optarg = optarg[1:]
+									}
+									if err := ps.collect(ps.addOptArg(p.opt, optarg)); err != nil {
+										return err
+									}
+									if p.opt.arity() > 1 {
+										waiting_opt = p.opt
+										optargs_needed = p.opt.arity() - 1
+									}
+									break
+								} else {
+									//Here s is the last character of the clump
+									needed, err := ps.bareOption(p.opt)
+									if err := ps.collect(err); err != nil {
+										return err
+									}
+									waiting_opt = p.opt
+									optargs_needed = needed
+								}
+							} else {
+								if err := ps.collect(ps.takeValue(p.flag, true)); err != nil {
+									return err
+								}
+							}
+						} else if err := ps.collect(ps.skipUnknown("-" + string(s), unrecognizedShort(s))); err != nil {
+							return err
+						}
+					}
+				}
+			} else if arg[0] == '+' {
+				//Negate clump
+				for _, s := range arg[1:] {
+					if p, ok := ps.lookupShort(s); ok {
+						ps.optionsParsed++
+						if p.takesArgument() {
+							if err := ps.collect(negatedOption(s)); err != nil {
+								return err
+							}
+						} else {
+							if err := ps.collect(ps.takeValue(p.flag, false)); err != nil {
+								return err
+							}
+						}
+					} else if err := ps.collect(ps.skipUnknown("+" + string(s), unrecognizedShort(s))); err != nil {
+						return err
+					}
+				}
+			} else {
+				ps.addRest(emit, arg, false)
+			}
+		}
+	}
+
+	if optargs_needed > 0 {
+		return ps.collect(&ErrMissingArgument{
+			Option:	waiting_opt.display(),
+			Count:	waiting_opt.arity(),
+		})
+	}
+	if expect_long {
+		return ps.collect(missingArgument("-" + string(ps.wExtension)))
+	}
+	return nil
+}
+
+//Parse os.Args with CommandLine.  Tolerates an empty os.Args, as when a
+//program is run with no argv at all.
+func GetOpts() ([]Rest, error) {
+	if len(os.Args) == 0 {
+		return []Rest{}, nil
+	}
+	return ArgParse(os.Args)
+}
+
+//Called by MustParse and ExitOnError to end the program.  Replaceable so
+//tests can observe the exit status instead of exiting.
+var ExitFunc func(code int) = os.Exit
+
+//What parsing does when it fails, like flag.ErrorHandling.
+type ErrorHandling int
+
+const(
+	//Return the error
+	ContinueOnError ErrorHandling = iota
+	//Print the error and help to standard error and exit with status 2,
+	//or with status 0 after automatic help or version output
+	ExitOnError
+	//Panic with the error, including ErrHelpRequested and
+	//ErrVersionRequested
+	PanicOnError
+)
+
+//Choose what parsing does when it fails.  The default, ContinueOnError,
+//suits libraries; ExitOnError suits simple programs.
+func (ps *Parser)SetErrorHandling(handling ErrorHandling) {
+	ps.errorHandling = handling
+}
+
+//Choose what parsing with CommandLine does when it fails.
+func SetErrorHandling(handling ErrorHandling) {
+	commandLine().SetErrorHandling(handling)
+}
+
+//Act on err as the error handling mode says.  Returns err if the program
+//is still running.
+func (ps *Parser)handleError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch ps.errorHandling {
+	case ExitOnError:
+		if errors.Is(err, ErrHelpRequested) || errors.Is(err, ErrVersionRequested) {
+			ExitFunc(0)
+		} else {
+			ps.reportError(err)
+			ExitFunc(2)
+		}
+	case PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
+//Print err, after the program name if there is one, and help to standard
+//error.
+func (ps *Parser)reportError(err error) {
+	msg := err.Error()
+	if ps.colorFor(os.Stderr) {
+		msg = ansiRed + msg + ansiReset
+	}
+	if name := ps.programName(); name != "" {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, msg)
+	} else {
+		fmt.Fprintln(os.Stderr, msg)
+	}
+	ps.writeHelp(os.Stderr)
+}
+
+//Parse args, returning the operands.  On error, print the error and help
+//to standard error and exit with status 2, like flag.ExitOnError.  After
+//automatic help or version output, exit with status 0.  The mode given to
+//SetErrorHandling is not applied, so nothing is reported twice.
+func (ps *Parser)MustParse(args []string) []Rest {
+	rest, err := ps.parseArgvUnhandled(args)
+	if errors.Is(err, ErrHelpRequested) || errors.Is(err, ErrVersionRequested) {
+		ExitFunc(0)
+		return nil
+	}
+	if err != nil {
+		ps.reportError(err)
+		ExitFunc(2)
+		return nil
+	}
+	return rest
+}
+
+//Parse args with CommandLine, exiting on error.
+func MustParse(args []string) []Rest {
+	return commandLine().MustParse(args)
+}
+
+//Names of the flags and options that were not passed, in registration
+//order.  Each is reported by its long option, or its short option if it
+//has no long option.  A flag turned off, as with +v, was passed.  Intended
+//to be called after parsing.
+func (ps *Parser)UnsetOptions() []string {
+	unset := make([]string, 0)
+	for _, p := range ps.params {
+		if !p.base().Changed() {
+			unset = append(unset, p.base().name())
+		}
+	}
+	return unset
+}
+
+//Names of the flags and options of CommandLine that were not passed.
+func UnsetOptions() []string {
+	return commandLine().UnsetOptions()
+}
+
+//Names of the flags negated more often than they were passed, in
+//registration order, as with +v when -v was never given.  This often means
+//the user misunderstood the flag, so tools may want to warn about it.
+func (ps *Parser)NegativeFlags() []string {
+	negative := make([]string, 0)
+	for _, p := range ps.params {
+		if p.flag != nil && p.flag.Count < 0 {
+			negative = append(negative, p.flag.name())
+		}
+	}
+	return negative
+}
+
+//Names of the flags of CommandLine negated more often than they were passed.
+func NegativeFlags() []string {
+	return commandLine().NegativeFlags()
+}
@@ -0,0 +1,135 @@
+package getopts
+
+import "testing"
+
+//Options registered through CommandLine's methods survive package level calls
+func TestCommandLineMethods(t *testing.T) {
+	resetParams()
+	output := CommandLine.NewOption('o', "output", "Output file")
+	output.Default = "out.txt"
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	_, err := ArgParse([]string{ "test", "-v" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if len(CommandLine.Options) != 1 || CommandLine.Options[0] != output || len(Options) != 1 {
+		t.Fatalf("Got options %v and %v", CommandLine.Options, Options)
+	}
+	if output.OptArg != "out.txt" || !verbose.Passed {
+		t.Fatalf("Got output %s and verbose %v", output.OptArg, verbose.Passed)
+	}
+	_, err = ArgParse([]string{ "test", "-o", "x.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	ResetValues()
+	if output.Passed || output.OptArg != "out.txt" {
+		t.Fatalf("ResetValues skipped the option, got %s", output.OptArg)
+	}
+}
+
+//Two parsers register the same names and parse independently of each
+//other and of CommandLine
+func TestIndependentParsers(t *testing.T) {
+	resetParams()
+	global := NewFlag('v', "verbose", "Increase verbosity")
+
+	first := NewParser()
+	firstVerbose := first.NewFlag('v', "verbose", "Increase verbosity")
+	firstFile := first.NewOption('f', "file", "File to read")
+
+	second := NewParser()
+	secondVerbose := second.NewFlag('v', "verbose", "Increase verbosity")
+	second.NewFlagLong("dry-run", "Show what would be done")
+
+	result, err := first.Parse([]string{ "first", "-v", "--file=a.txt", "b.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if !firstVerbose.Passed || firstFile.OptArg != "a.txt" {
+		t.Fatalf("First parser did not set its options")
+	}
+	if len(result.Rest) != 1 || result.Rest[0].Argument != "b.txt" {
+		t.Fatalf("Got %v, expected b.txt", result.Rest)
+	}
+	if secondVerbose.Passed || global.Passed {
+		t.Fatalf("Parsing one parser set another's flag")
+	}
+
+	_, err = second.Parse([]string{ "second", "--file=a.txt" })
+	if err == nil || err.Error() != "Unrecognized long option:  file" {
+		t.Fatalf("Got error %v, expected unrecognized --file", err)
+	}
+	_, err = first.Parse([]string{ "first", "--dry-run" })
+	if err == nil {
+		t.Fatalf("Expected --dry-run to be unrecognized by the first parser")
+	}
+	if len(Flags) != 1 || len(Options) != 0 {
+		t.Fatalf("Got %d flags and %d options in CommandLine, expected 1 and 0",
+			len(Flags), len(Options))
+	}
+}
+
+//ParseResult reports the operands, where '--' was, and how many options
+//were passed
+func TestParseResult(t *testing.T) {
+	ps := NewParser()
+	ps.NewFlag('v', "verbose", "Increase verbosity")
+	ps.NewOption('f', "file", "File to read")
+
+	result, err := ps.Parse([]string{ "prog", "-vv", "--file", "a.txt", "b.txt", "--", "-v" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if result.OptionsParsed != 3 {
+		t.Fatalf("Got %d options parsed, expected 3", result.OptionsParsed)
+	}
+	if result.DashIndex != 5 {
+		t.Fatalf("Got dash index %d, expected 5", result.DashIndex)
+	}
+	if len(result.Rest) != 2 || result.Rest[1].Argument != "-v" || !result.Rest[1].AfterDashes {
+		t.Fatalf("Got %v, expected b.txt and -v after '--'", result.Rest)
+	}
+
+	result, err = ps.Parse([]string{ "prog", "b.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if result.DashIndex != -1 || result.OptionsParsed != 0 {
+		t.Fatalf("Got dash index %d and %d options, expected -1 and 0",
+			result.DashIndex, result.OptionsParsed)
+	}
+}
+
+//Lenient parsing keeps unknown options as written and goes on parsing
+func TestLenient(t *testing.T) {
+	ps := NewParser()
+	verbose := ps.NewFlag('v', "verbose", "Increase verbosity")
+	quiet := ps.NewFlag('q', "quiet", "Decrease verbosity")
+	ps.SetLenient(true)
+
+	result, err := ps.Parse([]string{ "prog", "-x", "--race", "--count=2", "-vzq", "+y", "pkg" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	expected := []string{ "-x", "--race", "--count=2", "-z", "+y" }
+	if len(result.Unknown) != len(expected) {
+		t.Fatalf("Got unknown %v, expected %v", result.Unknown, expected)
+	}
+	for i := range expected {
+		if result.Unknown[i] != expected[i] {
+			t.Fatalf("Got unknown %v, expected %v", result.Unknown, expected)
+		}
+	}
+	if !verbose.Passed || !quiet.Passed {
+		t.Fatalf("Known flags around an unknown one were not set")
+	}
+	if len(result.Rest) != 1 || result.Rest[0].Argument != "pkg" {
+		t.Fatalf("Got %v, expected pkg", result.Rest)
+	}
+
+	ps.SetLenient(false)
+	if _, err := ps.Parse([]string{ "prog", "--race" }); err == nil {
+		t.Fatalf("Expected --race to be rejected when not lenient")
+	}
+}
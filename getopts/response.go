@@ -0,0 +1,64 @@
+package getopts
+
+import "fmt"
+import "os"
+import "strings"
+
+//How many response files may name other response files before giving up,
+//so files that name each other do not loop forever.
+const maxResponseDepth = 10
+
+const(
+	errResponseDepth = "Response files nested too deeply:  %s"
+	errResponseFile = "Response file %s:  %w"
+)
+
+//Replace an argument like @args.txt by the arguments in args.txt, for
+//command lines too long for the system, as on Windows.  Each line of the
+//file is one argument, with white space at either end removed.  Blank
+//lines and lines starting with # are skipped.  A line starting with @
+//names another response file, relative to the working directory like the
+//first.  Opt-args and arguments after '--' are not expanded.  Off by
+//default.
+func (ps *Parser)SetResponseFiles(enabled bool) {
+	ps.responseFiles = enabled
+}
+
+//Expand @file arguments for CommandLine.
+func SetResponseFiles(enabled bool) {
+	commandLine().SetResponseFiles(enabled)
+}
+
+//Whether arg names a response file to expand.
+func (ps *Parser)isResponseFile(arg string) bool {
+	return ps.responseFiles && len(arg) > 1 && arg[0] == '@'
+}
+
+//Arguments in the response file path, with the response files they name
+//expanded, recursively.
+func (ps *Parser)expandResponseFile(path string, depth int) ([]string, error) {
+	if depth >= maxResponseDepth {
+		return nil, fmt.Errorf(errResponseDepth, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(errResponseFile, path, err)
+	}
+	expanded := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		arg := strings.TrimSpace(line)
+		if arg == "" || arg[0] == '#' {
+			continue
+		}
+		if !ps.isResponseFile(arg) {
+			expanded = append(expanded, arg)
+			continue
+		}
+		more, err := ps.expandResponseFile(arg[1:], depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, more...)
+	}
+	return expanded, nil
+}
@@ -0,0 +1,63 @@
+package getopts
+
+import "testing"
+import "os"
+import "errors"
+import "path/filepath"
+
+//Write a response file in dir and return its path.
+func writeResponseFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	return path
+}
+
+//@file arguments are replaced by the lines of the file, recursively
+func TestResponseFiles(t *testing.T) {
+	resetParams()
+	dir := t.TempDir()
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	file := NewOption('f', "file", "File to read")
+	inner := writeResponseFile(t, dir, "inner.txt", "-v\r\nc.txt\n")
+	outer := writeResponseFile(t, dir, "outer.txt",
+		"# Options for the build\n--file\n  a file.txt  \n\n@" + inner + "\n")
+
+	rest, err := ArgParse([]string{ "test", "@" + outer, "b.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if len(rest) != 2 || rest[0].Argument != "@" + outer {
+		t.Fatalf("Response files should be off by default, got %v", rest)
+	}
+
+	SetResponseFiles(true)
+	rest, err = ArgParse([]string{ "test", "@" + outer, "b.txt", "--", "@" + inner })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if file.OptArg != "a file.txt" || verbose.Count != 1 {
+		t.Fatalf("Got file %s and count %d", file.OptArg, verbose.Count)
+	}
+	exp := []string{ "c.txt", "b.txt", "@" + inner }
+	if len(rest) != len(exp) {
+		t.Fatalf("Got %v expected %v", rest, exp)
+	}
+	for i := range exp {
+		if rest[i].Argument != exp[i] {
+			t.Fatalf("Got %v expected %v", rest, exp)
+		}
+	}
+
+	loop := filepath.Join(dir, "loop.txt")
+	writeResponseFile(t, dir, "loop.txt", "@" + loop + "\n")
+	_, err = ArgParse([]string{ "test", "@" + loop })
+	if err == nil || err.Error() != "Response files nested too deeply:  " + loop {
+		t.Fatalf("Got error %v, expected nesting too deep", err)
+	}
+	_, err = ArgParse([]string{ "test", "@" + filepath.Join(dir, "missing.txt") })
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Got error %v, expected a missing file", err)
+	}
+}
@@ -0,0 +1,72 @@
+package getopts
+
+import "fmt"
+
+//Kind of place a value came from.
+type SourceKind int
+
+const(
+	//No value has been given
+	SourceNone SourceKind = iota
+	//The option's Default
+	SourceDefault
+	//An environment variable
+	SourceEnv
+	//A config file
+	SourceConfig
+	//An argument on the command line
+	SourceCommandLine
+	//A call to Set
+	SourceSet
+)
+
+//Where the value of a flag or option came from, for debugging which of
+//the command line, environment, config file, and defaults won.
+type Source struct {
+	Kind	SourceKind
+	//Position of the argument for SourceCommandLine, counted as in
+	//Rest.Index
+	Index	int
+	//Environment variable for SourceEnv, or path of the config file for
+	//SourceConfig
+	Name	string
+}
+
+//Where the value came from, like "argument 3" or "environment variable
+//MYTOOL_JOBS".
+func (s Source)String() string {
+	switch s.Kind {
+	case SourceDefault:
+		return "default"
+	case SourceEnv:
+		return "environment variable " + s.Name
+	case SourceConfig:
+		return "config file " + s.Name
+	case SourceCommandLine:
+		return fmt.Sprintf("argument %d", s.Index)
+	case SourceSet:
+		return "Set"
+	}
+	return "none"
+}
+
+//Where a value being assigned now comes from.
+func (ps *Parser)valueSource() Source {
+	source := ps.origin
+	if source.Kind == SourceCommandLine {
+		source.Index = ps.argIndex
+	}
+	return source
+}
+
+//Where the current value came from.  For an option passed or set several
+//times, this is where the last opt-arg came from.
+func (o *option)Source() Source {
+	return o.source
+}
+
+//Whether the value was given on the command line, by the environment, by
+//a config file, or by Set, rather than being the default or never given.
+func (o *option)Changed() bool {
+	return o.source.Kind != SourceNone && o.source.Kind != SourceDefault
+}
@@ -0,0 +1,48 @@
+package getopts
+
+import "testing"
+
+//Each value records whether it came from the command line, environment,
+//config file, default, or Set
+func TestSource(t *testing.T) {
+	resetParams()
+	t.Setenv("TOOL_HOST", "example.com")
+	SetEnvPrefix("TOOL_")
+	output := NewOption('o', "output", "File to write")
+	host := NewOptionLong("host", "Server")
+	level := NewOptionLong("level", "Compression level")
+	mode := NewOptionLong("mode", "Mode")
+	mode.Default = "fast"
+	verbose := NewFlag('v', "verbose", "Increase verbosity")
+	quiet := NewFlag('q', "quiet", "Say less")
+	path := writeConfig(t, "tool.conf", "level = 3\n")
+	if err := LoadConfig(path); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	_, err := ArgParse([]string{ "test", "-v", "--output", "a.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	cases := []struct{
+		param	*option
+		exp	string
+	}{
+		{ &output.option, "argument 3" },
+		{ &verbose.option, "argument 1" },
+		{ &host.option, "environment variable TOOL_HOST" },
+		{ &level.option, "config file " + path },
+		{ &mode.option, "default" },
+		{ &quiet.option, "none" },
+	}
+	for _, c := range cases {
+		if s := c.param.Source().String(); s != c.exp {
+			t.Fatalf("Got source %s for %s, expected %s", s, c.param.display(), c.exp)
+		}
+	}
+	if !host.Changed() || mode.Changed() || quiet.Changed() {
+		t.Fatalf("Only values given somewhere should count as changed")
+	}
+	if err := Set("quiet", "true"); err != nil || quiet.Source().Kind != SourceSet {
+		t.Fatalf("Got source %v and error %v, expected Set", quiet.Source(), err)
+	}
+}
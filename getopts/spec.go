@@ -0,0 +1,162 @@
+package getopts
+
+import "fmt"
+import "unicode/utf8"
+
+//Kind of parameter a Spec describes.
+type SpecType int
+
+const(
+	//A flag, registered as a *Flag
+	SpecFlag SpecType = iota
+	//An option taking an argument, registered as an *Option
+	SpecOption
+)
+
+//Description of a flag or option for RegisterAll.
+type Spec struct {
+	//Short option, or 0 for none
+	Short	rune
+	//Long option, or empty for none
+	Long	string
+	Help	string
+	Type	SpecType
+	//Default of an option.  Ignored for flags.
+	Default	string
+}
+
+//Flags and options created by RegisterAll, keyed by long option, or by
+//short option for those without a long option.
+type Registered struct {
+	Flags	map[string]*Flag
+	Options	map[string]*Option
+}
+
+const(
+	errSpecNoName = "Spec has neither short nor long option:  %s"
+	errSpecShort = "Short option registered twice:  -%c"
+	errSpecLong = "Long option registered twice:  --%s"
+	errSpecSameName = "Short option -%c and long option --%c would be different options"
+)
+
+//Check that specs can all be registered, without registering any.  The
+//checks are those made when registering each spec in turn, against what is
+//registered and the specs before it, but fail with an error instead of a
+//panic.
+func (ps *Parser)checkSpecs(specs []Spec) error {
+	shorts := make(map[rune]bool)
+	longs := make(map[string]bool)
+	for i, spec := range specs {
+		s, l := spec.Short, spec.Long
+		if s == 0 && l == "" {
+			return fmt.Errorf(errSpecNoName, spec.Help)
+		}
+		if s != 0 {
+			_, taken := ps.paramsByShort[s]
+			_, aliased := ps.aliasesByShort[s]
+			if taken || aliased || shorts[s] {
+				return fmt.Errorf(errSpecShort, s)
+			}
+			_, long := ps.paramsByLong[string(s)]
+			if ps.unifyShortLong && (long || longs[string(s)]) {
+				return fmt.Errorf(errSpecSameName, s, s)
+			}
+			shorts[s] = true
+		}
+		if l != "" {
+			_, taken := ps.paramsByLong[l]
+			_, aliased := ps.aliasesByLong[l]
+			if taken || aliased || longs[l] {
+				return fmt.Errorf(errSpecLong, l)
+			}
+			if r, _ := utf8.DecodeRuneInString(l); ps.unifyShortLong && utf8.RuneCountInString(l) == 1 {
+				_, short := ps.paramsByShort[r]
+				if short || (shorts[r] && r != s) {
+					return fmt.Errorf(errSpecSameName, r, r)
+				}
+			}
+			longs[l] = true
+		}
+		if err := ps.specArity(spec, specs[:i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//With strict registration, the error checkArity would panic with when
+//registering spec after those registered and the specs before it.
+func (ps *Parser)specArity(spec Spec, before []Spec) error {
+	if !ps.strictRegistration {
+		return nil
+	}
+	takesArg := spec.Type == SpecOption
+	for _, p := range ps.params {
+		o := p.base()
+		if err := arityMismatch(spec.Short, spec.Long, takesArg, o.ShortOpt, o.LongOpt, o.takesArg); err != nil {
+			return err
+		}
+	}
+	for _, other := range before {
+		if err := arityMismatch(spec.Short, spec.Long, takesArg, other.Short, other.Long,
+			other.Type == SpecOption); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Register a flag with whichever of the short option s and long option l
+//are given.
+func (ps *Parser)newFlag(s rune, l string, h string) *Flag {
+	if s == 0 {
+		return ps.NewFlagLong(l, h)
+	} else if l == "" {
+		return ps.NewFlagShort(s, h)
+	}
+	return ps.NewFlag(s, l, h)
+}
+
+//Register an option with whichever of the short option s and long option l
+//are given.
+func (ps *Parser)newOption(s rune, l string, h string) *Option {
+	if s == 0 {
+		return ps.NewOptionLong(l, h)
+	} else if l == "" {
+		return ps.NewOptionShort(s, h)
+	}
+	return ps.NewOption(s, l, h)
+}
+
+//Register a flag or option for every spec, for table driven setup.  If any
+//spec has no name, or could not be registered in turn, say because its name
+//is taken by another parameter or an alias, nothing is registered and an
+//error is returned instead of a panic.
+func (ps *Parser)RegisterAll(specs []Spec) (Registered, error) {
+	registered := Registered{
+		Flags:		make(map[string]*Flag),
+		Options:	make(map[string]*Option),
+	}
+	if err := ps.checkSpecs(specs); err != nil {
+		return registered, err
+	}
+
+	for _, spec := range specs {
+		if spec.Type == SpecFlag {
+			flag := ps.newFlag(spec.Short, spec.Long, spec.Help)
+			registered.Flags[flag.name()] = flag
+		} else {
+			opt := ps.newOption(spec.Short, spec.Long, spec.Help)
+			opt.Default = spec.Default
+			opt.OptArg = spec.Default
+			registered.Options[opt.name()] = opt
+		}
+	}
+	return registered, nil
+}
+
+//Register a flag or option with CommandLine for every spec.
+func RegisterAll(specs []Spec) (Registered, error) {
+	defer syncCommandLine()
+	return commandLine().RegisterAll(specs)
+}
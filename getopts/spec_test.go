@@ -0,0 +1,103 @@
+package getopts
+
+import "testing"
+
+//Registering from specs creates every parameter, or none on a collision
+func TestRegisterAll(t *testing.T) {
+	resetParams()
+	registered, err := RegisterAll([]Spec{
+		{
+			Short:	'v',
+			Long:	"verbose",
+			Help:	"Increase verbosity",
+			Type:	SpecFlag,
+		},
+		{
+			Long:		"output",
+			Help:		"Output file",
+			Type:		SpecOption,
+			Default:	"out.txt",
+		},
+		{
+			Short:	'j',
+			Help:	"Number of jobs",
+			Type:	SpecOption,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	_, err = ArgParse([]string{ "test", "-v", "-j", "4" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if !registered.Flags["verbose"].Passed {
+		t.Fatalf("-v should set verbose")
+	}
+	if registered.Options["output"].OptArg != "out.txt" {
+		t.Fatalf("Got output %s, expected default", registered.Options["output"].OptArg)
+	}
+	if registered.Options["j"].OptArg != "4" {
+		t.Fatalf("Got jobs %s, expected 4", registered.Options["j"].OptArg)
+	}
+
+	resetParams()
+	_, err = RegisterAll([]Spec{
+		{
+			Long:	"color",
+			Type:	SpecFlag,
+		},
+		{
+			Long:	"color",
+			Type:	SpecOption,
+		},
+	})
+	if err == nil {
+		t.Fatalf("Repeated long option should be an error")
+	}
+	if len(CommandLine.params) != 0 {
+		t.Fatalf("Nothing should be registered after an error")
+	}
+}
+
+//Names taken by aliases, unified short and long names, and mismatched
+//arity under strict registration are errors, and nothing is registered
+func TestRegisterAllConflicts(t *testing.T) {
+	cases := []struct{
+		setup	func()
+		specs	[]Spec
+		exp	string
+	}{
+		{
+			func() { RegisterAlias('a', "", []string{ "-v" }) },
+			[]Spec{ { Short: 'x', Long: "ex" }, { Short: 'a', Long: "all" } },
+			"Short option registered twice:  -a",
+		},
+		{
+			func() { RegisterAlias(0, "all", []string{ "-v" }) },
+			[]Spec{ { Short: 'x', Long: "ex" }, { Long: "all" } },
+			"Long option registered twice:  --all",
+		},
+		{
+			func() { SetUnifyShortLong(true) },
+			[]Spec{ { Short: 'x', Long: "ex" }, { Long: "f" }, { Short: 'f' } },
+			"Short option -f and long option --f would be different options",
+		},
+		{
+			func() { SetStrictRegistration(true) },
+			[]Spec{ { Short: 'x', Long: "ex" }, { Long: "file", Type: SpecOption }, { Short: 'f' } },
+			"Short option -f and long option --file disagree on whether they take an argument",
+		},
+	}
+	for _, c := range cases {
+		resetParams()
+		c.setup()
+		_, err := RegisterAll(c.specs)
+		if err == nil || err.Error() != c.exp {
+			t.Fatalf("Got error %v, expected '%s'", err, c.exp)
+		}
+		if len(CommandLine.params) != 0 {
+			t.Fatalf("Nothing should be registered after '%s'", c.exp)
+		}
+	}
+}
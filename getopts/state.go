@@ -0,0 +1,101 @@
+package getopts
+
+import "strconv"
+import "fmt"
+import "io"
+
+//Values of every registered flag and option at some point in time, so a
+//later parse can be compared with an earlier one.
+type State struct {
+	//Names in registration order
+	names	[]string
+	//Values as they are, so changes to sensitive options are seen
+	values	map[string]string
+	//Names of sensitive options, whose values changes show as ***
+	sensitive	map[string]bool
+}
+
+//A flag or option whose value differs between two states.
+type Change struct {
+	//Long option, or short option if there is no long option
+	Name	string
+	//Value in the earlier state.  For options this is OptArg, for flags
+	//it is Count.  Both values are *** for a sensitive option.
+	Old	string
+	//Value in the later state
+	New	string
+}
+
+//Value of a parameter as recorded in a State.
+func stateValue(p parameter) string {
+	if p.opt != nil {
+		return p.opt.OptArg
+	}
+	return strconv.Itoa(p.flag.Count)
+}
+
+//Record the current values of every registered flag and option.
+func (ps *Parser)Snapshot() State {
+	state := State{
+		names:	make([]string, 0, len(ps.params)),
+		values:	make(map[string]string, len(ps.params)),
+		sensitive:	make(map[string]bool),
+	}
+	for _, p := range ps.params {
+		name := p.base().name()
+		state.names = append(state.names, name)
+		state.values[name] = stateValue(p)
+		if p.opt != nil && p.opt.sensitive {
+			state.sensitive[name] = true
+		}
+	}
+	return state
+}
+
+//Record the current values of every flag and option of CommandLine.
+func Snapshot() State {
+	return commandLine().Snapshot()
+}
+
+//Flags and options whose values differ between two snapshots, in the
+//registration order of after.  Useful for logging what a reload changed.
+func DiffState(before, after State) []Change {
+	changes := make([]Change, 0)
+	for _, name := range after.names {
+		old := before.values[name]
+		if after.values[name] != old {
+			change := Change{
+				Name:	name,
+				Old:	old,
+				New:	after.values[name],
+			}
+			if before.sensitive[name] || after.sensitive[name] {
+				change.Old = "***"
+				change.New = "***"
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+//Write the value of every flag and option as name=value lines, for
+//debugging.  Options show OptArg and flags show Count.  Sensitive options
+//show ***.
+func (ps *Parser)DumpState(w io.Writer) error {
+	for _, p := range ps.params {
+		value := stateValue(p)
+		if p.opt != nil {
+			value = p.opt.masked(value)
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", p.base().name(), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Write the value of every flag and option of CommandLine.
+func DumpState(w io.Writer) error {
+	return commandLine().DumpState(w)
+}
@@ -0,0 +1,83 @@
+package getopts
+
+import "testing"
+import "strings"
+
+//Only values that changed between parses are reported
+func TestDiffState(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	level := NewOptionLong("level", "Log level")
+	level.OptArg = "info"
+	NewOption('o', "output", "Output file")
+	_, err := ArgParse([]string{ "test", "-v", "-o", "out.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	before := Snapshot()
+	_, err = ArgParse([]string{ "test", "--level=debug", "-o", "out.txt" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	changes := DiffState(before, Snapshot())
+	if len(changes) != 1 {
+		t.Fatalf("Got changes %v, expected only level", changes)
+	}
+	exp := Change{
+		Name:	"level",
+		Old:	"info",
+		New:	"debug",
+	}
+	if changes[0] != exp {
+		t.Fatalf("Got %v expected %v", changes[0], exp)
+	}
+}
+
+//A change to a sensitive option is reported without its values
+func TestDiffStateSensitive(t *testing.T) {
+	resetParams()
+	token := NewOptionLong("token", "API token")
+	token.SetSensitive(true)
+	_, err := ArgParse([]string{ "test", "--token=a" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	before := Snapshot()
+	_, err = ArgParse([]string{ "test", "--token=b" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	changes := DiffState(before, Snapshot())
+	exp := Change{
+		Name:	"token",
+		Old:	"***",
+		New:	"***",
+	}
+	if len(changes) != 1 || changes[0] != exp {
+		t.Fatalf("Got changes %v expected %v", changes, exp)
+	}
+}
+
+//Sensitive values are masked in DumpState but usable by the program
+func TestDumpStateSensitive(t *testing.T) {
+	resetParams()
+	NewFlag('v', "verbose", "Increase verbosity")
+	user := NewOptionLong("user", "User name")
+	token := NewOptionLong("token", "API token")
+	token.SetSensitive(true)
+	_, err := ArgParse([]string{ "test", "-v", "--user=me", "--token=s3cret" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	var b strings.Builder
+	if err := DumpState(&b); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	exp := "verbose=1\nuser=me\ntoken=***\n"
+	if b.String() != exp {
+		t.Fatalf("Got\n%s\nexpected\n%s", b.String(), exp)
+	}
+	if token.OptArg != "s3cret" || user.OptArg != "me" {
+		t.Fatalf("Got token %s, expected the real value", token.OptArg)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package getopts
+
+import "os"
+
+//Terminals cannot be detected here, so f is never taken to be one.
+func terminalSize(f *os.File) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,27 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package getopts
+
+import "os"
+import "syscall"
+import "unsafe"
+
+//Window size as filled in by the TIOCGWINSZ ioctl.
+type winsize struct {
+	rows	uint16
+	cols	uint16
+	xpixel	uint16
+	ypixel	uint16
+}
+
+//Number of columns of the terminal f is attached to, and whether it is a
+//terminal at all.
+func terminalSize(f *os.File) (int, bool) {
+	var ws winsize
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(),
+		uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0, false
+	}
+	return int(ws.cols), true
+}
@@ -0,0 +1,677 @@
+package getopts
+
+import "fmt"
+import "strconv"
+import "time"
+import "strings"
+import "net"
+import "net/netip"
+import "math"
+
+//Option whose argument is an integer, converted as it is parsed.
+type IntOption struct {
+	*Option
+	//The most recent opt-arg as an integer
+	Value	int
+}
+
+//Option whose argument is a floating point number, converted as it is parsed.
+type Float64Option struct {
+	*Option
+	//The most recent opt-arg as a number
+	Value	float64
+}
+
+//Option whose argument is a duration like 1m30s, converted as it is parsed.
+type DurationOption struct {
+	*Option
+	//The most recent opt-arg as a duration
+	Value	time.Duration
+}
+
+//Option whose argument is an IPv4 or IPv6 address, converted as it is
+//parsed.
+type IPOption struct {
+	*Option
+	//The most recent opt-arg as an address
+	Value	net.IP
+}
+
+//Option whose argument is a CIDR prefix like 10.0.0.0/8, converted as it
+//is parsed.
+type PrefixOption struct {
+	*Option
+	//The most recent opt-arg as a prefix
+	Value	netip.Prefix
+}
+
+//A host and port, as Get reads a HostPortOption.
+type HostPort struct {
+	Host	string
+	Port	int
+}
+
+//Option whose argument is a host and port like example.com:443 or
+//[::1]:8080, split as it is parsed.
+type HostPortOption struct {
+	*Option
+	//Host of the most recent opt-arg, without brackets
+	Host	string
+	//Port of the most recent opt-arg
+	Port	int
+}
+
+//Option whose argument is a size in bytes like 10K, 5MiB, or 1GB,
+//converted as it is parsed.
+type SizeOption struct {
+	*Option
+	//The most recent opt-arg as a number of bytes
+	Value	int64
+}
+
+//Option whose argument is a point in time, like 2024-05-01T12:00:00Z,
+//yesterday, or -2h, converted as it is parsed.
+type TimeOption struct {
+	*Option
+	//The most recent opt-arg as a time
+	Value	time.Time
+	//Layouts tried after time.RFC3339
+	layouts	[]string
+}
+
+//Option whose argument is a boolean like yes or false, converted as it is
+//parsed.  Unlike a flag, it cannot be passed without an argument.
+type BoolOption struct {
+	*Option
+	//The most recent opt-arg as a boolean
+	Value	bool
+}
+
+//A type of the program's own that can be set from an opt-arg, like
+//flag.Value, for options taking IP addresses, label selectors, and so on.
+type Value interface {
+	//Set from an opt-arg, or return an error saying why it is not valid
+	Set(string) error
+	//The current value as it would be passed
+	String() string
+}
+
+//Option whose argument is parsed by a Value.
+type ValueOption struct {
+	*Option
+	//Set with each opt-arg as it is parsed
+	Value	Value
+}
+
+//What a map option does when a key is given again.
+type DuplicateKeys int
+
+const(
+	//The last value given for a key wins
+	DuplicateKeysLast DuplicateKeys = iota
+	//The first value given for a key is kept
+	DuplicateKeysFirst
+	//Giving a key more than once is an error
+	DuplicateKeysError
+)
+
+//Option whose arguments are key=value pairs, like -D defines for a
+//compiler, collected into a map.
+type MapOption struct {
+	*Option
+	//Every key passed, with its value
+	Value	map[string]string
+	duplicates	DuplicateKeys
+}
+
+//Option whose arguments are lists like a,b,c, merged across repeats.
+type ListOption struct {
+	*Option
+	//Every item of every opt-arg, in order
+	Value	[]string
+}
+
+//Error for an opt-arg of o that cannot be converted.
+func invalidArg(o *Option, format, arg string) error {
+	return invalidValue(o.display(), o.masked(arg), format, o.display(), o.masked(arg))
+}
+
+//Sets *v back to the zero value of its type.
+func zeroValue[T any](v *T) func() {
+	return func() {
+		var zero T
+		*v = zero
+	}
+}
+
+//Register an option whose argument must be an integer.  An argument that
+//is not one makes parsing fail with an error naming the option.  Either s
+//or l may be left out by passing 0 or an empty string.
+func (ps *Parser)NewIntOption(s rune, l string, h string) *IntOption {
+	opt := &IntOption{ Option: ps.newOption(s, l, h) }
+	opt.convert = func(arg string) error {
+		v, err := strconv.Atoi(arg)
+		if err != nil {
+			return invalidArg(opt.Option, errNotInteger, arg)
+		}
+		opt.Value = v
+		return nil
+	}
+	opt.zero = zeroValue(&opt.Value)
+	opt.typed = func() any {
+		return opt.Value
+	}
+	return opt
+}
+
+func NewIntOption(s rune, l string, h string) *IntOption {
+	defer syncCommandLine()
+	return commandLine().NewIntOption(s, l, h)
+}
+
+//Use v when the option is not passed.
+func (o *IntOption)SetDefault(v int) {
+	o.Default = strconv.Itoa(v)
+	o.Value = v
+}
+
+//Register an option whose argument must be a floating point number.
+func (ps *Parser)NewFloat64Option(s rune, l string, h string) *Float64Option {
+	opt := &Float64Option{ Option: ps.newOption(s, l, h) }
+	opt.convert = func(arg string) error {
+		v, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return invalidArg(opt.Option, errNotFloat, arg)
+		}
+		opt.Value = v
+		return nil
+	}
+	opt.zero = zeroValue(&opt.Value)
+	opt.typed = func() any {
+		return opt.Value
+	}
+	return opt
+}
+
+func NewFloat64Option(s rune, l string, h string) *Float64Option {
+	defer syncCommandLine()
+	return commandLine().NewFloat64Option(s, l, h)
+}
+
+//Use v when the option is not passed.
+func (o *Float64Option)SetDefault(v float64) {
+	o.Default = strconv.FormatFloat(v, 'g', -1, 64)
+	o.Value = v
+}
+
+//Register an option whose argument must be a duration accepted by
+//time.ParseDuration, like 300ms or 2h45m.
+func (ps *Parser)NewDurationOption(s rune, l string, h string) *DurationOption {
+	opt := &DurationOption{ Option: ps.newOption(s, l, h) }
+	opt.convert = func(arg string) error {
+		v, err := time.ParseDuration(arg)
+		if err != nil {
+			return invalidArg(opt.Option, errNotDuration, arg)
+		}
+		opt.Value = v
+		return nil
+	}
+	opt.zero = zeroValue(&opt.Value)
+	opt.typed = func() any {
+		return opt.Value
+	}
+	return opt
+}
+
+func NewDurationOption(s rune, l string, h string) *DurationOption {
+	defer syncCommandLine()
+	return commandLine().NewDurationOption(s, l, h)
+}
+
+//Use v when the option is not passed.
+func (o *DurationOption)SetDefault(v time.Duration) {
+	o.Default = v.String()
+	o.Value = v
+}
+
+//Register an option whose argument must be a boolean, accepted in the same
+//forms as --flag=value:  true, yes, y, t, and their opposites, in any case.
+func (ps *Parser)NewBoolOption(s rune, l string, h string) *BoolOption {
+	opt := &BoolOption{ Option: ps.newOption(s, l, h) }
+	opt.convert = func(arg string) error {
+		v, err := parseFlagOpt(opt.name(), arg)
+		if err != nil {
+			return invalidArg(opt.Option, errNotBool, arg)
+		}
+		opt.Value = v
+		return nil
+	}
+	opt.zero = zeroValue(&opt.Value)
+	opt.typed = func() any {
+		return opt.Value
+	}
+	return opt
+}
+
+func NewBoolOption(s rune, l string, h string) *BoolOption {
+	defer syncCommandLine()
+	return commandLine().NewBoolOption(s, l, h)
+}
+
+//Use v when the option is not passed.
+func (o *BoolOption)SetDefault(v bool) {
+	o.Default = strconv.FormatBool(v)
+	o.Value = v
+}
+
+//Register an option whose argument must be one of choices, like
+//--format json.  Any other argument makes parsing fail with an error
+//listing the choices, which help also shows.
+func (ps *Parser)NewChoiceOption(s rune, l string, h string, choices []string) *Option {
+	opt := ps.newOption(s, l, h)
+	opt.choices = choices
+	opt.convert = func(arg string) error {
+		for _, choice := range choices {
+			if arg == choice {
+				return nil
+			}
+		}
+		return invalidValue(opt.display(), opt.masked(arg), errNotChoice,
+			opt.display(), strings.Join(choices, ", "), opt.masked(arg))
+	}
+	return opt
+}
+
+func NewChoiceOption(s rune, l string, h string, choices []string) *Option {
+	defer syncCommandLine()
+	return commandLine().NewChoiceOption(s, l, h, choices)
+}
+
+//Register an option whose opt-args are each passed to v.Set as they are
+//parsed.  An error from Set makes parsing fail with an error naming the
+//option, which wraps the error from Set for errors.Is and errors.As.
+func (ps *Parser)NewValueOption(s rune, l string, h string, v Value) *ValueOption {
+	opt := &ValueOption{
+		Option:	ps.newOption(s, l, h),
+		Value:	v,
+	}
+	opt.convert = func(arg string) error {
+		if err := v.Set(arg); err != nil {
+			return &ErrInvalidValue{
+				Option:	opt.display(),
+				Value:	opt.masked(arg),
+				msg:	fmt.Sprintf(errNotValue, opt.display(), opt.masked(arg), opt.maskedError(err, arg)),
+				cause:	err,
+			}
+		}
+		return nil
+	}
+	opt.typed = func() any {
+		return opt.Value
+	}
+	return opt
+}
+
+func NewValueOption(s rune, l string, h string, v Value) *ValueOption {
+	defer syncCommandLine()
+	return commandLine().NewValueOption(s, l, h, v)
+}
+
+//Register an option taking key=value arguments, so -D key=val -D other=2
+//gives the map {key: val, other: 2}.  The value may be empty, as in key=,
+//and may contain '=', but an argument without '=' is an error.  By default
+//the last value for a repeated key wins; see SetDuplicateKeys.
+func (ps *Parser)NewMapOption(s rune, l string, h string) *MapOption {
+	opt := &MapOption{
+		Option:	ps.newOption(s, l, h),
+		Value:	make(map[string]string),
+	}
+	opt.convert = func(arg string) error {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return invalidArg(opt.Option, errNotKeyValue, arg)
+		}
+		//Start over after Clear
+		if !opt.Passed {
+			clear(opt.Value)
+		}
+		if _, seen := opt.Value[key]; seen {
+			switch opt.duplicates {
+			case DuplicateKeysFirst:
+				return nil
+			case DuplicateKeysError:
+				return invalidValue(opt.display(), opt.masked(arg), errDuplicateKey,
+					key, opt.display(), opt.masked(arg))
+			}
+		}
+		opt.Value[key] = value
+		return nil
+	}
+	opt.zero = func() {
+		clear(opt.Value)
+	}
+	opt.typed = func() any {
+		return opt.Value
+	}
+	return opt
+}
+
+func NewMapOption(s rune, l string, h string) *MapOption {
+	defer syncCommandLine()
+	return commandLine().NewMapOption(s, l, h)
+}
+
+//Choose what happens when a key is given more than once.
+func (o *MapOption)SetDuplicateKeys(policy DuplicateKeys) {
+	o.duplicates = policy
+}
+
+//Register an option taking a comma separated list, so
+//--include=a,b --include=c gives a, b, and c, in Value and in OptArgs.
+//It is split as with SetSplitOn and SetSplitEscapes, so a backslash makes
+//the next character literal, as in a\,b for the single item a,b.  Help
+//describes the format.  See SetSeparator to split on something else.
+func (ps *Parser)NewListOption(s rune, l string, h string) *ListOption {
+	opt := &ListOption{ Option: ps.newOption(s, l, h) }
+	opt.SetSplitOn(",")
+	opt.SetSplitEscapes(true)
+	opt.convert = func(arg string) error {
+		fields, err := opt.split(arg)
+		if err != nil {
+			return err
+		}
+		//Start over after Clear
+		if !opt.Passed {
+			opt.Value = nil
+		}
+		opt.Value = append(opt.Value, fields...)
+		return nil
+	}
+	opt.zero = zeroValue(&opt.Value)
+	opt.typed = func() any {
+		return opt.Value
+	}
+	return opt
+}
+
+func NewListOption(s rune, l string, h string) *ListOption {
+	defer syncCommandLine()
+	return commandLine().NewListOption(s, l, h)
+}
+
+//Split lists on sep instead of a comma.  Panics if sep is empty.
+func (o *ListOption)SetSeparator(sep string) {
+	if sep == "" {
+		panic("List separator must not be empty")
+	}
+	o.splitOn = sep
+}
+
+//Register an option whose argument must be an IP address, like 192.0.2.1
+//or 2001:db8::1.
+func (ps *Parser)NewIPOption(s rune, l string, h string) *IPOption {
+	opt := &IPOption{ Option: ps.newOption(s, l, h) }
+	opt.convert = func(arg string) error {
+		v := net.ParseIP(arg)
+		if v == nil {
+			return invalidArg(opt.Option, errNotIP, arg)
+		}
+		opt.Value = v
+		return nil
+	}
+	opt.zero = zeroValue(&opt.Value)
+	opt.typed = func() any {
+		return opt.Value
+	}
+	return opt
+}
+
+func NewIPOption(s rune, l string, h string) *IPOption {
+	defer syncCommandLine()
+	return commandLine().NewIPOption(s, l, h)
+}
+
+//Use v when the option is not passed.
+func (o *IPOption)SetDefault(v net.IP) {
+	o.Default = v.String()
+	o.Value = v
+}
+
+//Register an option whose argument must be a CIDR prefix accepted by
+//netip.ParsePrefix, like 10.0.0.0/8 or 2001:db8::/32.
+func (ps *Parser)NewPrefixOption(s rune, l string, h string) *PrefixOption {
+	opt := &PrefixOption{ Option: ps.newOption(s, l, h) }
+	opt.convert = func(arg string) error {
+		v, err := netip.ParsePrefix(arg)
+		if err != nil {
+			return invalidArg(opt.Option, errNotPrefix, arg)
+		}
+		opt.Value = v
+		return nil
+	}
+	opt.zero = zeroValue(&opt.Value)
+	opt.typed = func() any {
+		return opt.Value
+	}
+	return opt
+}
+
+func NewPrefixOption(s rune, l string, h string) *PrefixOption {
+	defer syncCommandLine()
+	return commandLine().NewPrefixOption(s, l, h)
+}
+
+//Use v when the option is not passed.
+func (o *PrefixOption)SetDefault(v netip.Prefix) {
+	o.Default = v.String()
+	o.Value = v
+}
+
+//Register an option whose argument must be a host and a port from 0 to
+//65535, like example.com:443, with an IPv6 host in brackets, like
+//[::1]:8080.  The host may be empty, as in :8080.
+func (ps *Parser)NewHostPortOption(s rune, l string, h string) *HostPortOption {
+	opt := &HostPortOption{ Option: ps.newOption(s, l, h) }
+	opt.convert = func(arg string) error {
+		host, port, err := net.SplitHostPort(arg)
+		if err != nil {
+			return invalidArg(opt.Option, errNotHostPort, arg)
+		}
+		n, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			return invalidArg(opt.Option, errNotHostPort, arg)
+		}
+		opt.Host = host
+		opt.Port = int(n)
+		return nil
+	}
+	opt.zero = func() {
+		opt.Host = ""
+		opt.Port = 0
+	}
+	opt.typed = func() any {
+		return HostPort{ Host: opt.Host, Port: opt.Port }
+	}
+	return opt
+}
+
+func NewHostPortOption(s rune, l string, h string) *HostPortOption {
+	defer syncCommandLine()
+	return commandLine().NewHostPortOption(s, l, h)
+}
+
+//Use host and port when the option is not passed.
+func (o *HostPortOption)SetDefault(host string, port int) {
+	o.Default = net.JoinHostPort(host, strconv.Itoa(port))
+	o.Host = host
+	o.Port = port
+}
+
+//Forms of size accepted, as help describes them.
+const sizeSyntax = "a size like 10K, 5MiB, or 1GB; K and KiB are 1024 bytes, KB is 1000, and so on"
+
+//Bytes in each unit of size, in lower case.  As in coreutils, a bare
+//letter is a power of 1024.
+var sizeUnits = map[string]int64{
+	"":	1,
+	"b":	1,
+	"k":	1 << 10,
+	"kib":	1 << 10,
+	"kb":	1e3,
+	"m":	1 << 20,
+	"mib":	1 << 20,
+	"mb":	1e6,
+	"g":	1 << 30,
+	"gib":	1 << 30,
+	"gb":	1e9,
+	"t":	1 << 40,
+	"tib":	1 << 40,
+	"tb":	1e12,
+	"p":	1 << 50,
+	"pib":	1 << 50,
+	"pb":	1e15,
+	"e":	1 << 60,
+	"eib":	1 << 60,
+	"eb":	1e18,
+}
+
+//Bytes in a size like 10K or 1.5GB, and whether it is one.  Fractions of a
+//byte are dropped.
+func parseSize(arg string) (int64, bool) {
+	i := strings.IndexFunc(arg, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(arg)
+	}
+	number, unit := arg[:i], strings.ToLower(strings.TrimSpace(arg[i:]))
+	scale, ok := sizeUnits[unit]
+	if !ok || number == "" {
+		return 0, false
+	}
+	if n, err := strconv.ParseInt(number, 10, 64); err == nil {
+		if n > math.MaxInt64 / scale {
+			return 0, false
+		}
+		return n * scale, true
+	}
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil || f * float64(scale) >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f * float64(scale)), true
+}
+
+//Register an option whose argument must be a size in bytes, with SI units
+//like KB and MB for powers of 1000 and IEC units like KiB and MiB, or
+//bare letters like K and M, for powers of 1024.  Units are not case
+//sensitive.  Help lists the forms accepted.
+func (ps *Parser)NewSizeOption(s rune, l string, h string) *SizeOption {
+	opt := &SizeOption{ Option: ps.newOption(s, l, h) }
+	opt.syntax = sizeSyntax
+	opt.convert = func(arg string) error {
+		v, ok := parseSize(arg)
+		if !ok {
+			return invalidArg(opt.Option, errNotSize, arg)
+		}
+		opt.Value = v
+		return nil
+	}
+	opt.zero = zeroValue(&opt.Value)
+	opt.typed = func() any {
+		return opt.Value
+	}
+	return opt
+}
+
+func NewSizeOption(s rune, l string, h string) *SizeOption {
+	defer syncCommandLine()
+	return commandLine().NewSizeOption(s, l, h)
+}
+
+//Use v bytes when the option is not passed.
+func (o *SizeOption)SetDefault(v int64) {
+	o.Default = strconv.FormatInt(v, 10)
+	o.Value = v
+}
+
+//Current time for relative times, replaced in tests.
+var timeNow = time.Now
+
+//Words accepted by time options, and the day each means relative to now.
+var relativeDays = map[string]int{
+	"today":	0,
+	"yesterday":	-1,
+	"tomorrow":	1,
+}
+
+//Every form o accepts, for help and errors.
+func (o *TimeOption)forms() string {
+	layouts := append([]string{ time.RFC3339 }, o.layouts...)
+	return strings.Join(layouts, ", ") + ", now, today, yesterday, tomorrow, or a duration from now like -2h or +30m"
+}
+
+//Time arg means, or false if it is none of the forms o accepts.
+func (o *TimeOption)parseTime(arg string) (time.Time, bool) {
+	now := timeNow()
+	if arg == "now" {
+		return now, true
+	}
+	if days, ok := relativeDays[arg]; ok {
+		y, m, d := now.Date()
+		return time.Date(y, m, d + days, 0, 0, 0, 0, now.Location()), true
+	}
+	if strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "+") {
+		if d, err := time.ParseDuration(arg); err == nil {
+			return now.Add(d), true
+		}
+	}
+	for _, layout := range append([]string{ time.RFC3339 }, o.layouts...) {
+		if v, err := time.ParseInLocation(layout, arg, time.Local); err == nil {
+			return v, true
+		}
+	}
+	return time.Time{}, false
+}
+
+//Register an option whose argument must be a time in RFC 3339 form, like
+//2024-05-01T12:00:00Z, or relative to now:  now, today, yesterday, or
+//tomorrow, the last three meaning midnight, or a signed duration like -2h.
+//SetLayouts accepts other forms.  Help and errors list the forms accepted.
+func (ps *Parser)NewTimeOption(s rune, l string, h string) *TimeOption {
+	opt := &TimeOption{ Option: ps.newOption(s, l, h) }
+	opt.syntax = "a time like " + opt.forms()
+	opt.convert = func(arg string) error {
+		v, ok := opt.parseTime(arg)
+		if !ok {
+			return invalidValue(opt.display(), opt.masked(arg), errNotTime,
+				opt.display(), opt.masked(arg), opt.forms())
+		}
+		opt.Value = v
+		return nil
+	}
+	opt.zero = zeroValue(&opt.Value)
+	opt.typed = func() any {
+		return opt.Value
+	}
+	return opt
+}
+
+func NewTimeOption(s rune, l string, h string) *TimeOption {
+	defer syncCommandLine()
+	return commandLine().NewTimeOption(s, l, h)
+}
+
+//Also accept times in layouts, as given to time.Parse, like
+//"2006-01-02" or time.Kitchen, tried in order after RFC 3339.  Times
+//without a zone are local.
+func (o *TimeOption)SetLayouts(layouts ...string) {
+	o.layouts = layouts
+	o.syntax = "a time like " + o.forms()
+}
+
+//Use v when the option is not passed.
+func (o *TimeOption)SetDefault(v time.Time) {
+	o.Default = v.Format(time.RFC3339)
+	o.Value = v
+}
@@ -0,0 +1,310 @@
+package getopts
+
+import "testing"
+import "time"
+import "strings"
+import "errors"
+import "fmt"
+
+//Typed options convert their arguments while parsing
+func TestTypedOptions(t *testing.T) {
+	resetParams()
+	jobs := NewIntOption('j', "jobs", "Number of jobs")
+	ratio := NewFloat64Option('r', "ratio", "Compression ratio")
+	timeout := NewDurationOption('t', "timeout", "Time to wait")
+	color := NewBoolOption('c', "color", "Colorize output")
+	_, err := ArgParse([]string{ "test", "-j4", "--ratio=0.5", "-t", "1m30s", "--color=yes" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if jobs.Value != 4 || ratio.Value != 0.5 || !color.Value {
+		t.Fatalf("Got %d %f %v", jobs.Value, ratio.Value, color.Value)
+	}
+	if timeout.Value != 90 * time.Second || timeout.OptArg != "1m30s" {
+		t.Fatalf("Got timeout %v", timeout.Value)
+	}
+}
+
+//An argument that cannot be converted is an error naming the option
+func TestTypedOptionInvalid(t *testing.T) {
+	cases := []struct {
+		register	func()
+		arg		string
+		exp		string
+	}{
+		{ func() { NewIntOption('j', "jobs", "Jobs") }, "--jobs=four",
+			"Argument to option --jobs is not an integer:  four" },
+		{ func() { NewFloat64Option('r', "ratio", "Ratio") }, "-rhalf",
+			"Argument to option --ratio is not a number:  half" },
+		{ func() { NewDurationOption('t', "timeout", "Timeout") }, "--timeout=5",
+			"Argument to option --timeout is not a duration:  5" },
+		{ func() { NewBoolOption('c', "color", "Color") }, "--color=maybe",
+			"Argument to option --color is not a boolean:  maybe" },
+		{ func() { NewSizeOption('s', "size", "Size") }, "--size=10Q",
+			"Argument to option --size is not a size:  10Q" },
+		{ func() { NewSizeOption('s', "size", "Size") }, "--size=9E",
+			"Argument to option --size is not a size:  9E" },
+		{ func() { NewIPOption('a', "addr", "Address") }, "--addr=10.0.0.256",
+			"Argument to option --addr is not an IP address:  10.0.0.256" },
+		{ func() { NewPrefixOption('n', "net", "Network") }, "--net=10.0.0.0/33",
+			"Argument to option --net is not a CIDR prefix:  10.0.0.0/33" },
+		{ func() { NewHostPortOption('l', "listen", "Listen") }, "--listen=localhost:http",
+			"Argument to option --listen is not host:port:  localhost:http" },
+		{ func() { NewHostPortOption('l', "listen", "Listen") }, "--listen=localhost",
+			"Argument to option --listen is not host:port:  localhost" },
+	}
+	for _, c := range cases {
+		resetParams()
+		c.register()
+		_, err := ArgParse([]string{ "test", c.arg })
+		if err == nil || err.Error() != c.exp {
+			t.Fatalf("Got error %v, expected '%s'", err, c.exp)
+		}
+	}
+}
+
+//Size options accept SI and IEC units, and help lists them
+func TestSizeOption(t *testing.T) {
+	resetParams()
+	size := NewSizeOption('s', "size", "Cache size")
+	cases := map[string]int64{
+		"512":		512,
+		"10K":		10240,
+		"10k":		10240,
+		"5MiB":		5 << 20,
+		"1GB":		1000000000,
+		"1.5KB":	1500,
+		"2 TiB":	2 << 40,
+	}
+	for arg, exp := range cases {
+		_, err := ArgParse([]string{ "test", "--size", arg })
+		if err != nil {
+			t.Fatalf("Error %s", err)
+		}
+		if size.Value != exp {
+			t.Fatalf("Got %d bytes for %s, expected %d", size.Value, arg, exp)
+		}
+	}
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	if !strings.Contains(b.String(), "a size like 10K, 5MiB, or 1GB") {
+		t.Fatalf("Help should describe sizes, got\n%s", b.String())
+	}
+}
+
+//Time options accept RFC 3339, extra layouts, and times relative to now
+func TestTimeOption(t *testing.T) {
+	resetParams()
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	timeNow = func() time.Time {
+		return now
+	}
+	defer func() {
+		timeNow = time.Now
+	}()
+	since := NewTimeOption('s', "since", "Show entries since")
+	since.SetLayouts("2006-01-02")
+	cases := map[string]time.Time{
+		"2024-04-01T08:00:00Z":	time.Date(2024, 4, 1, 8, 0, 0, 0, time.UTC),
+		"2024-04-01":		time.Date(2024, 4, 1, 0, 0, 0, 0, time.Local),
+		"now":			now,
+		"yesterday":		time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+		"-2h":			now.Add(-2 * time.Hour),
+	}
+	for arg, exp := range cases {
+		_, err := ArgParse([]string{ "test", "--since=" + arg })
+		if err != nil {
+			t.Fatalf("Error %s", err)
+		}
+		if !since.Value.Equal(exp) {
+			t.Fatalf("Got %v for %s, expected %v", since.Value, arg, exp)
+		}
+	}
+	_, err := ArgParse([]string{ "test", "--since=last week" })
+	exp := "Argument to option --since is not a time:  last week, expected one of " +
+		"2006-01-02T15:04:05Z07:00, 2006-01-02, now, today, yesterday, tomorrow, " +
+		"or a duration from now like -2h or +30m"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v, expected '%s'", err, exp)
+	}
+}
+
+//Network options convert addresses, prefixes, and host:port pairs
+func TestNetworkOptions(t *testing.T) {
+	resetParams()
+	addr := NewIPOption('a', "addr", "Address")
+	network := NewPrefixOption('n', "net", "Network")
+	listen := NewHostPortOption('l', "listen", "Listen")
+	upstream := NewHostPortOption('u', "upstream", "Upstream")
+	upstream.SetDefault("example.com", 443)
+	_, err := ArgParse([]string{ "test", "-a", "2001:db8::1", "--net=10.0.0.0/8", "-l[::1]:8080" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if addr.Value.String() != "2001:db8::1" || network.Value.String() != "10.0.0.0/8" {
+		t.Fatalf("Got address %v and network %v", addr.Value, network.Value)
+	}
+	if listen.Host != "::1" || listen.Port != 8080 {
+		t.Fatalf("Got host %s and port %d", listen.Host, listen.Port)
+	}
+	if upstream.Host != "example.com" || upstream.Port != 443 || upstream.OptArg != "example.com:443" {
+		t.Fatalf("Got default host %s and port %d", upstream.Host, upstream.Port)
+	}
+}
+
+//Typed defaults set Value when the option is not passed
+func TestTypedDefault(t *testing.T) {
+	resetParams()
+	timeout := NewDurationOption('t', "timeout", "Time to wait")
+	timeout.SetDefault(30 * time.Second)
+	jobs := NewIntOption('j', "jobs", "Number of jobs")
+	jobs.SetDefault(2)
+	_, err := ArgParse([]string{ "test", "-j8" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if timeout.Value != 30 * time.Second || timeout.OptArg != "30s" || jobs.Value != 8 {
+		t.Fatalf("Got timeout %v and jobs %d", timeout.Value, jobs.Value)
+	}
+}
+
+//Choice options accept only their choices, which help lists
+func TestChoiceOption(t *testing.T) {
+	resetParams()
+	format := NewChoiceOption('f', "format", "Output format", []string{ "json", "yaml", "text" })
+	_, err := ArgParse([]string{ "test", "--format=yaml" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if format.OptArg != "yaml" {
+		t.Fatalf("Got %s, expected yaml", format.OptArg)
+	}
+	_, err = ArgParse([]string{ "test", "-f", "xml" })
+	exp := "Argument to option --format must be one of json, yaml, text:  xml"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v, expected '%s'", err, exp)
+	}
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	if b.String() != "-f/--format {json|yaml|text} Output format\n" {
+		t.Fatalf("Got help %s", b.String())
+	}
+}
+
+//Comma separated key=value labels, as a Value of the program's own
+type labels map[string]string
+
+var errNoEquals = errors.New("missing '='")
+
+func (l labels)Set(arg string) error {
+	for _, pair := range strings.Split(arg, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return errNoEquals
+		}
+		l[k] = v
+	}
+	return nil
+}
+
+func (l labels)String() string {
+	return fmt.Sprint(map[string]string(l))
+}
+
+//A Value is set with each opt-arg, and its errors name the option
+func TestValueOption(t *testing.T) {
+	resetParams()
+	selector := labels{}
+	opt := NewValueOption('l', "selector", "Labels to match", selector)
+	_, err := ArgParse([]string{ "test", "-lapp=web,tier=front", "--selector", "env=prod" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if len(selector) != 3 || selector["tier"] != "front" || selector["env"] != "prod" {
+		t.Fatalf("Got %v", selector)
+	}
+	if opt.Value.String() != "map[app:web env:prod tier:front]" {
+		t.Fatalf("Got %s", opt.Value.String())
+	}
+
+	_, err = ArgParse([]string{ "test", "--selector=app" })
+	exp := "Argument to option --selector is not valid:  app:  missing '='"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v, expected '%s'", err, exp)
+	}
+	var invalid *ErrInvalidValue
+	if !errors.Is(err, errNoEquals) || !errors.As(err, &invalid) || invalid.Value != "app" {
+		t.Fatalf("Error %v does not wrap the error from Set", err)
+	}
+}
+
+//Map options collect key=value pairs, handling repeated keys as chosen
+func TestMapOption(t *testing.T) {
+	resetParams()
+	defines := NewMapOption('D', "define", "Define a macro")
+	_, err := ArgParse([]string{ "test", "-DDEBUG=1", "-D", "NAME=a=b", "--define=DEBUG=2", "-DEMPTY=" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if len(defines.Value) != 3 || defines.Value["DEBUG"] != "2" ||
+		defines.Value["NAME"] != "a=b" || defines.Value["EMPTY"] != "" {
+		t.Fatalf("Got %v", defines.Value)
+	}
+
+	defines.Clear()
+	defines.SetDuplicateKeys(DuplicateKeysFirst)
+	_, err = ArgParse([]string{ "test", "-DDEBUG=1", "-DDEBUG=2" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if len(defines.Value) != 1 || defines.Value["DEBUG"] != "1" {
+		t.Fatalf("Got %v, expected the first DEBUG to be kept", defines.Value)
+	}
+
+	defines.Clear()
+	defines.SetDuplicateKeys(DuplicateKeysError)
+	_, err = ArgParse([]string{ "test", "-DDEBUG=1", "-DDEBUG=2" })
+	exp := "Key DEBUG given to option --define more than once:  DEBUG=2"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v, expected '%s'", err, exp)
+	}
+	_, err = ArgParse([]string{ "test", "-DDEBUG" })
+	exp = "Argument to option --define is not key=value:  DEBUG"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v, expected '%s'", err, exp)
+	}
+}
+
+//List options split on the separator, honor escapes, and merge repeats
+func TestListOption(t *testing.T) {
+	resetParams()
+	include := NewListOption('I', "include", "Directories to search")
+	_, err := ArgParse([]string{ "test", "--include=a,b\\,c", "-I", "d", "-Ie,,f\\\\" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	exp := []string{ "a", "b,c", "d", "e", "", "f\\" }
+	if len(include.Value) != len(exp) {
+		t.Fatalf("Got %q expected %q", include.Value, exp)
+	}
+	for i := range exp {
+		if include.Value[i] != exp[i] || include.OptArgs[i] != exp[i] {
+			t.Fatalf("Got %q and %q expected %q", include.Value, include.OptArgs, exp)
+		}
+	}
+
+	include.Clear()
+	include.SetSeparator(":")
+	_, err = ArgParse([]string{ "test", "-I/usr/lib:/opt/a\\:b" })
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	if len(include.Value) != 2 || include.Value[1] != "/opt/a:b" {
+		t.Fatalf("Got %q", include.Value)
+	}
+	var b strings.Builder
+	CommandLine.writeHelp(&b)
+	if b.String() != "-I/--include Directories to search (separated by ':', \\: for a literal ':')\n" {
+		t.Fatalf("Got help %s", b.String())
+	}
+}
@@ -0,0 +1,148 @@
+package getopts
+
+import "fmt"
+import "errors"
+import "slices"
+import "sort"
+
+const(
+	errDefUnregisteredFlag = "%s is required when %s is set, but %s is not registered"
+	errDefLinkUnregistered = "Linked count %s and option %s are not both registered"
+	errDefAfterDash = "At least %d and at most %d arguments after '--' is impossible"
+	errDefArityRemainder = "%s takes %d arguments but also consumes the remainder"
+	errDefAlias = "Alias %s expands to unregistered option:  %s"
+	errDefGroup = "Exclusive group member %s is not registered"
+	errDefRequires = "%s requires %s, which is not registered"
+	errDefArityOptional = "%s takes %d arguments but its argument is optional"
+	errDefRequiresExclusive = "%s requires %s, but they are in the same exclusive group"
+	errDefRequiredIfExclusive = "%s is required when %s is set, but they are in the same exclusive group"
+)
+
+//Whether o is registered.
+func (ps *Parser)isRegistered(o *option) bool {
+	for _, p := range ps.params {
+		if p.base() == o {
+			return true
+		}
+	}
+	return false
+}
+
+//Whether a and b are in the same exclusive group, so cannot both be passed.
+func (ps *Parser)exclusive(a, b *option) bool {
+	for _, group := range ps.groups {
+		if slices.Contains(group.members, a) && slices.Contains(group.members, b) {
+			return true
+		}
+	}
+	return false
+}
+
+//Whether arg is registered as a flag, option, or alias, if it looks like
+//one.  Values, like x in --mode x, cannot be told apart from operands so
+//are accepted.
+func (ps *Parser)aliasTokenValid(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+		return true
+	}
+	if _, ok := ps.lookupAlias(arg); ok {
+		return true
+	}
+	if arg[1] == '-' {
+		return ps.isLong(arg[2:])
+	}
+	for _, s := range arg[1:] {
+		p, ok := ps.lookupShort(s)
+		if !ok {
+			return false
+		}
+		if p.takesArgument() {
+			return true
+		}
+	}
+	return true
+}
+
+//Check that the registered options and the constraints between them make
+//sense, without parsing anything, so mistakes in setup can be caught by a
+//test.  Returns every problem found, joined into one error, or nil.
+func (ps *Parser)ValidateDefinitions() error {
+	ps.prepareShortTable()
+	problems := make([]error, 0)
+	for _, p := range ps.params {
+		o := p.base()
+		if o.requiredIf != nil && !ps.isRegistered(&o.requiredIf.option) {
+			problems = append(problems, fmt.Errorf(errDefUnregisteredFlag,
+				o.display(), o.requiredIf.display(), o.requiredIf.display()))
+		}
+		if o.requiredIf != nil && ps.exclusive(o, &o.requiredIf.option) {
+			problems = append(problems, fmt.Errorf(errDefRequiredIfExclusive,
+				o.display(), o.requiredIf.display()))
+		}
+		for _, other := range o.requires {
+			if !ps.isRegistered(other) {
+				problems = append(problems, fmt.Errorf(errDefRequires,
+					o.display(), other.display()))
+			}
+			if ps.exclusive(o, other) {
+				problems = append(problems, fmt.Errorf(errDefRequiresExclusive,
+					o.display(), other.display()))
+			}
+		}
+		if p.opt != nil && p.opt.consumesRemainder && p.opt.arity() > 1 {
+			problems = append(problems, fmt.Errorf(errDefArityRemainder,
+				o.display(), p.opt.arity()))
+		}
+		if p.opt != nil && p.opt.optionalArg && p.opt.arity() > 1 {
+			problems = append(problems, fmt.Errorf(errDefArityOptional,
+				o.display(), p.opt.arity()))
+		}
+	}
+	for _, link := range ps.countLinks {
+		if !ps.isRegistered(&link.flag.option) || !ps.isRegistered(&link.opt.option) {
+			problems = append(problems, fmt.Errorf(errDefLinkUnregistered,
+				link.flag.display(), link.opt.display()))
+		}
+	}
+	for _, group := range ps.groups {
+		for _, o := range group.members {
+			if !ps.isRegistered(o) {
+				problems = append(problems, fmt.Errorf(errDefGroup, o.display()))
+			}
+		}
+	}
+	if ps.maxAfterDash >= 0 && ps.minAfterDash > ps.maxAfterDash {
+		problems = append(problems, fmt.Errorf(errDefAfterDash, ps.minAfterDash, ps.maxAfterDash))
+	}
+	aliases := ps.aliasExpansions()
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, arg := range aliases[name] {
+			if !ps.aliasTokenValid(arg) {
+				problems = append(problems, fmt.Errorf(errDefAlias, name, arg))
+			}
+		}
+	}
+	return errors.Join(problems...)
+}
+
+//Check the definitions of CommandLine without parsing anything.
+func ValidateDefinitions() error {
+	return commandLine().ValidateDefinitions()
+}
+
+//Every alias expansion, keyed by the alias as it is passed.
+func (ps *Parser)aliasExpansions() map[string][]string {
+	all := make(map[string][]string)
+	for s, expansion := range ps.aliasesByShort {
+		all["-" + string(s)] = expansion
+	}
+	for l, expansion := range ps.aliasesByLong {
+		all["--" + l] = expansion
+	}
+	return all
+}
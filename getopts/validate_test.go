@@ -0,0 +1,40 @@
+package getopts
+
+import "testing"
+
+//A consistent definition passes and contradictions are all reported
+func TestValidateDefinitions(t *testing.T) {
+	resetParams()
+	remote := NewFlagLong("remote", "Use remote")
+	upload := NewOptionLong("upload", "Upload destination")
+	upload.SetRequiredIf(remote)
+	NewFlagShort('x', "Extract")
+	RegisterAlias('a', "", []string{ "-x", "--upload=host" })
+	SetMinAfterDash(1)
+	if err := ValidateDefinitions(); err != nil {
+		t.Fatalf("Valid definitions reported %s", err)
+	}
+
+	SetMaxAfterDash(0)
+	RegisterAlias(0, "fast", []string{ "--mode=fast" })
+	err := ValidateDefinitions()
+	exp := "At least 1 and at most 0 arguments after '--' is impossible\n" +
+		"Alias --fast expands to unregistered option:  --mode=fast"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v expected\n%s", err, exp)
+	}
+
+	resetParams()
+	json := NewFlagLong("json", "JSON output")
+	xml := NewFlagLong("xml", "XML output")
+	schema := NewOptionLong("schema", "Schema file")
+	json.Requires(xml)
+	schema.SetRequiredIf(json)
+	NewExclusiveGroup(json, xml, schema)
+	err = ValidateDefinitions()
+	exp = "--json requires --xml, but they are in the same exclusive group\n" +
+		"--schema is required when --json is set, but they are in the same exclusive group"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("Got error %v expected\n%s", err, exp)
+	}
+}
@@ -0,0 +1,94 @@
+//Package validators provides reusable checks for getopts Option.Validate,
+//like
+//
+//	dir.Validate = validators.ExistingDir
+//	port.Validate = validators.IntRange(1, 65535)
+package validators
+
+import "fmt"
+import "net/url"
+import "os"
+import "regexp"
+import "strconv"
+import "strings"
+
+const(
+	errNotFile = "%s is not a file"
+	errNotDir = "%s is not a directory"
+	errNotInteger = "%s is not an integer"
+	errOutOfRange = "%d is not between %d and %d"
+	errNoMatch = "%s does not match %s"
+	errNotURL = "%s is not a URL with a scheme and host"
+	errNotOneOf = "%s is not one of %s"
+)
+
+//Accept the name of an existing file that is not a directory.
+func ExistingFile(arg string) error {
+	info, err := os.Stat(arg)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf(errNotFile, arg)
+	}
+	return nil
+}
+
+//Accept the name of an existing directory.
+func ExistingDir(arg string) error {
+	info, err := os.Stat(arg)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf(errNotDir, arg)
+	}
+	return nil
+}
+
+//Accept integers from min to max, inclusive.
+func IntRange(min, max int) func(string) error {
+	return func(arg string) error {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf(errNotInteger, arg)
+		}
+		if n < min || n > max {
+			return fmt.Errorf(errOutOfRange, n, min, max)
+		}
+		return nil
+	}
+}
+
+//Accept arguments matching pat, which must compile.  Anchor it with ^ and $
+//to match the whole argument.
+func Regexp(pat string) func(string) error {
+	re := regexp.MustCompile(pat)
+	return func(arg string) error {
+		if !re.MatchString(arg) {
+			return fmt.Errorf(errNoMatch, arg, pat)
+		}
+		return nil
+	}
+}
+
+//Accept absolute URLs with a scheme and host, like https://example.com/x.
+func URL(arg string) error {
+	u, err := url.Parse(arg)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf(errNotURL, arg)
+	}
+	return nil
+}
+
+//Accept only the given choices.
+func OneOf(choices ...string) func(string) error {
+	return func(arg string) error {
+		for _, choice := range choices {
+			if arg == choice {
+				return nil
+			}
+		}
+		return fmt.Errorf(errNotOneOf, arg, strings.Join(choices, ", "))
+	}
+}
@@ -0,0 +1,38 @@
+package validators
+
+import "testing"
+import "os"
+import "path/filepath"
+
+//Each validator accepts good arguments and rejects bad ones
+func TestValidators(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("Error %s", err)
+	}
+	cases := []struct{
+		name	string
+		check	func(string) error
+		good	string
+		bad	string
+	}{
+		{ "ExistingFile", ExistingFile, file, dir },
+		{ "ExistingDir", ExistingDir, dir, file },
+		{ "IntRange", IntRange(1, 10), "10", "11" },
+		{ "Regexp", Regexp("^[a-z]+$"), "abc", "aBc" },
+		{ "URL", URL, "https://example.com/x", "example.com" },
+		{ "OneOf", OneOf("fast", "slow"), "slow", "medium" },
+	}
+	for _, c := range cases {
+		if err := c.check(c.good); err != nil {
+			t.Fatalf("%s rejected %s:  %s", c.name, c.good, err)
+		}
+		if err := c.check(c.bad); err == nil {
+			t.Fatalf("%s accepted %s", c.name, c.bad)
+		}
+	}
+	if err := ExistingFile(filepath.Join(dir, "missing")); err == nil {
+		t.Fatalf("ExistingFile accepted a missing file")
+	}
+}